@@ -0,0 +1,179 @@
+package resthelper
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// the struct tags DecodeRequest reads to bind request parameters into fields, in addition to the json body
+const (
+	pathTag   = "path"
+	queryTag  = "query"
+	headerTag = "header"
+)
+
+var paramTags = []string{pathTag, queryTag, headerTag}
+
+// Param describes a struct field bound to a path variable, query parameter or header
+type Param struct {
+	In    string // "path", "query" or "header"
+	Name  string
+	Index []int // for reflect.Value.FieldByIndex
+	Field reflect.StructField
+}
+
+var paramCache sync.Map // reflect.Type -> []Param
+
+// Params lists the fields of a struct type tagged for parameter binding, descending into embedded structs
+func Params(t reflect.Type) []Param {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := paramCache.Load(t); ok {
+		return cached.([]Param)
+	}
+	fields := collectParams(t, nil)
+	paramCache.Store(t, fields)
+	return fields
+}
+
+func collectParams(t reflect.Type, parentIndex []int) []Param {
+	fields := []Param{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parentIndex...), i)
+		tagged := false
+		for _, tag := range paramTags {
+			if name, ok := field.Tag.Lookup(tag); ok && name != "" && name != "-" && field.IsExported() {
+				fields = append(fields, Param{In: tag, Name: name, Index: index, Field: field})
+				tagged = true
+				break
+			}
+		}
+		if !tagged && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectParams(field.Type, index)...)
+		}
+	}
+	return fields
+}
+
+func paramValues(r *http.Request, param Param) []string {
+	switch param.In {
+	case pathTag:
+		if value, ok := mux.Vars(r)[param.Name]; ok {
+			return []string{value}
+		}
+		return nil
+	case queryTag:
+		return r.URL.Query()[param.Name]
+	default:
+		return r.Header.Values(param.Name)
+	}
+}
+
+// bindParams fills the path, query and header tagged fields of target (a pointer to a struct) from r
+// parameters missing from the request leave their fields untouched
+func bindParams(r *http.Request, target any) *HttpError {
+	value := reflect.ValueOf(target).Elem()
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	for _, param := range Params(value.Type()) {
+		values := paramValues(r, param)
+		if len(values) == 0 {
+			continue
+		}
+		if err := setFromStrings(value.FieldByIndex(param.Index), values); err != nil {
+			httpErr := NewHttpErrF(http.StatusBadRequest, "invalid %s parameter %q (field %s): %v", param.In, param.Name, param.Field.Name, err)
+			httpErr.Extensions = map[string]any{"parameter": param.Name, "in": param.In}
+			return httpErr
+		}
+	}
+	return nil
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// setFromStrings converts raw parameter values into target; slices take every value, anything else takes the first
+func setFromStrings(target reflect.Value, values []string) error {
+	if reflect.PointerTo(target.Type()).Implements(textUnmarshalerType) {
+		return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	switch target.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(target.Type().Elem())
+		if err := setFromStrings(elem.Elem(), values); err != nil {
+			return err
+		}
+		target.Set(elem)
+		return nil
+	case reflect.Slice:
+		slice := reflect.MakeSlice(target.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setFromStrings(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+		return nil
+	}
+	return setFromString(target, values[0])
+}
+
+func setFromString(target reflect.Value, value string) error {
+	if target.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(duration))
+		return nil
+	}
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		target.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported parameter type %s", target.Type())
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package resthelper_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+type testPaging struct {
+	Limit  int  `query:"limit" json:"-"`
+	Offset uint `query:"offset" json:"-"`
+}
+
+type testBoundRequest struct {
+	testPaging
+	ID      int64         `path:"id" json:"-"`
+	Tenant  string        `header:"X-Tenant" json:"-"`
+	Verbose *bool         `query:"verbose" json:"-"`
+	Since   time.Time     `query:"since" json:"-"`
+	Timeout time.Duration `query:"timeout" json:"-"`
+	Tags    []string      `query:"tag" json:"-"`
+	Client  netip.Addr    `header:"X-Client-IP" json:"-"`
+	Name    string
+}
+
+func testBoundHandler(r *http.Request, input testBoundRequest) (testBoundRequest, *resthelper.HttpError) {
+	return input, nil
+}
+
+func serveBound(method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{id}", resthelper.JsonToJsonWrapper(testBoundHandler))
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBindParams(t *testing.T) {
+	var bound testBoundRequest
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{id}", resthelper.JsonToJsonWrapper(func(r *http.Request, input testBoundRequest) (bool, *resthelper.HttpError) {
+		bound = input
+		return true, nil
+	}))
+	req := httptest.NewRequest("POST", "/items/42?limit=10&offset=5&verbose=true&since=2024-01-02T03:04:05Z&timeout=1m30s&tag=a&tag=b", strings.NewReader(`{"Name":"Steve"}`))
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("X-Client-IP", "10.0.0.1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatal("expected", http.StatusOK, "got", rec.Code, rec.Body.String())
+	}
+
+	if bound.Name != "Steve" {
+		t.Error("body not decoded alongside parameters:", bound.Name)
+	}
+	if bound.ID != 42 || bound.Limit != 10 || bound.Offset != 5 || bound.Tenant != "acme" {
+		t.Error("scalar parameters not bound:", bound)
+	}
+	if bound.Verbose == nil || !*bound.Verbose {
+		t.Error("pointer parameter not bound")
+	}
+	if !bound.Since.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Error("time parameter not bound:", bound.Since)
+	}
+	if bound.Timeout != 90*time.Second {
+		t.Error("duration parameter not bound:", bound.Timeout)
+	}
+	if len(bound.Tags) != 2 || bound.Tags[0] != "a" || bound.Tags[1] != "b" {
+		t.Error("slice parameter not bound:", bound.Tags)
+	}
+	if bound.Client != netip.MustParseAddr("10.0.0.1") {
+		t.Error("TextUnmarshaler parameter not bound:", bound.Client)
+	}
+}
+
+func TestBindParamsConversionError(t *testing.T) {
+	rec := serveBound("GET", "/items/42?limit=lots", "", map[string]string{"Accept": "application/problem+json"})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatal("expected", http.StatusBadRequest, "got", rec.Code)
+	}
+	problem := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem["parameter"] != "limit" || problem["in"] != "query" {
+		t.Error("problem does not name the bad parameter:", problem)
+	}
+	if detail, _ := problem["detail"].(string); !strings.Contains(detail, "Limit") {
+		t.Error("detail does not name the bad field:", detail)
+	}
+}
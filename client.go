@@ -0,0 +1,299 @@
+package resthelper
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Route describes a json endpoint once, so the server registering it and the clients calling it agree on its method, path and types
+// define routes in a package both sides import, e.g. var GetUser = resthelper.NewRoute[GetUserRequest, User]("GET", "/users/{id}")
+type Route[REQUEST_TYPE any, RESPONSE_TYPE any] struct {
+	Method string
+	Path   string // the full mux path template, like /users/{id}
+}
+
+func NewRoute[REQUEST_TYPE any, RESPONSE_TYPE any](method, path string) Route[REQUEST_TYPE, RESPONSE_TYPE] {
+	return Route[REQUEST_TYPE, RESPONSE_TYPE]{
+		Method: method,
+		Path:   path,
+	}
+}
+
+// Register wraps handler like JsonToJsonWrapper and registers it on router for the route's method and path, recording it in the route table
+func (route Route[REQUEST_TYPE, RESPONSE_TYPE]) Register(router *mux.Router, handler JsonRequestHandler[REQUEST_TYPE, RESPONSE_TYPE], opts ...RouteOption) *mux.Route {
+	return Register(router, route.Method, route.Path, JsonToJson(handler), opts...)
+}
+
+// Client calls routes served by resthelper
+type Client struct {
+	BaseURL    string       // the scheme and host of the server, plus any prefix its routes are mounted under
+	HTTPClient *http.Client // http.DefaultClient if nil
+	Header     http.Header  // sent with every request, e.g. for authorization
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: baseURL,
+		Header:  http.Header{},
+	}
+}
+
+// Call sends request to the route on client's server and decodes the response
+// fields tagged path, query or header are sent the way DecodeRequest binds them, and the rest of request as the json body (except for GET and HEAD requests)
+// error responses are returned as an *HttpError rebuilt from the server's problem details, or its plain text message
+// a RESPONSE_TYPE of Response[T] also receives the response's status, headers and cookies
+func (route Route[REQUEST_TYPE, RESPONSE_TYPE]) Call(ctx context.Context, client *Client, request REQUEST_TYPE) (RESPONSE_TYPE, error) {
+	var response RESPONSE_TYPE
+	httpRequest, err := route.newRequest(ctx, client, request)
+	if err != nil {
+		return response, err
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpResponse, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return response, err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode < http.StatusOK || httpResponse.StatusCode >= http.StatusMultipleChoices {
+		return response, ReadHttpError(httpResponse)
+	}
+	body, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return response, err
+	}
+	var target any = &response
+	if receiver, ok := target.(responseReceiver); ok {
+		target = receiver.receiveResponse(httpResponse.StatusCode, httpResponse.Header, httpResponse.Cookies())
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, target); err != nil {
+			return response, fmt.Errorf("failed to decode %s %s response: %w", route.Method, route.Path, err)
+		}
+	}
+	return response, nil
+}
+
+func (route Route[REQUEST_TYPE, RESPONSE_TYPE]) newRequest(ctx context.Context, client *Client, request REQUEST_TYPE) (*http.Request, error) {
+	header := http.Header{}
+	for key, values := range client.Header {
+		header[key] = append([]string{}, values...)
+	}
+	header.Set("Accept", "application/json, "+problemJsonContentType)
+	if id := RequestID(ctx); id != "" {
+		// calls made while serving a request carry its ID along, in the header the serving wrapper uses, so both services' logs can be tied together
+		if idHeader := configFromContext(ctx).requestIDHeader; idHeader != "" && header.Get(idHeader) == "" {
+			header.Set(idHeader, id)
+		}
+	}
+	if span := SpanFromContext(ctx).SpanContext(); span.IsValid() {
+		header.Set("traceparent", span.TraceParent())
+	}
+	pathValues := map[string]string{}
+	query := url.Values{}
+
+	value := reflect.ValueOf(&request).Elem()
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		for _, param := range Params(value.Type()) {
+			// zero values are left out, since DecodeRequest leaves fields for missing parameters at their zero value anyway; path variables can't be left out
+			values, err := paramStrings(value.FieldByIndex(param.Index), param.In != pathTag)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s parameter %q (field %s): %w", param.In, param.Name, param.Field.Name, err)
+			}
+			switch param.In {
+			case pathTag:
+				if len(values) > 0 {
+					pathValues[param.Name] = values[0]
+				}
+			case queryTag:
+				query[param.Name] = append(query[param.Name], values...)
+			default:
+				for _, headerValue := range values {
+					header.Add(param.Name, headerValue)
+				}
+			}
+		}
+	}
+
+	path, err := expandPath(route.Path, pathValues)
+	if err != nil {
+		return nil, err
+	}
+	target := strings.TrimSuffix(client.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if route.Method != http.MethodGet && route.Method != http.MethodHead {
+		encoded, err := json.Marshal(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s %s request: %w", route.Method, route.Path, err)
+		}
+		body = bytes.NewReader(encoded)
+		header.Set("Content-Type", "application/json")
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, route.Method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header = header
+	return httpRequest, nil
+}
+
+// expandPath fills in the variables of a mux path template, escaping their values
+func expandPath(template string, values map[string]string) (string, error) {
+	path := strings.Builder{}
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			path.WriteString(template)
+			return path.String(), nil
+		}
+		end, depth := start, 0
+		for ; end < len(template); end++ {
+			if template[end] == '{' {
+				depth++
+			} else if template[end] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if end == len(template) {
+			return "", fmt.Errorf("unbalanced braces in path template %q", template)
+		}
+		name, _, _ := strings.Cut(template[start+1:end], ":")
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("no value for path variable %q", name)
+		}
+		path.WriteString(template[:start])
+		path.WriteString(url.PathEscape(value))
+		template = template[end+1:]
+	}
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// paramStrings formats a bound field as the raw parameter values setFromStrings would parse back into it; slices give one value per element
+func paramStrings(field reflect.Value, skipZero bool) ([]string, error) {
+	if skipZero && field.IsZero() {
+		return nil, nil
+	}
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil, nil
+		}
+		return paramStrings(field.Elem(), false)
+	}
+	if field.Kind() == reflect.Slice && !field.Type().Implements(textMarshalerType) {
+		values := make([]string, field.Len())
+		for i := range values {
+			value, err := paramString(field.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+	value, err := paramString(field)
+	if err != nil {
+		return nil, err
+	}
+	return []string{value}, nil
+}
+
+func paramString(field reflect.Value) (string, error) {
+	if field.Type().Implements(textMarshalerType) {
+		text, err := field.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(textMarshalerType) {
+		text, err := field.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String(), nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'g', -1, field.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported parameter type %s", field.Type())
+	}
+}
+
+// ReadHttpError rebuilds the HttpError behind an error response from a resthelper server, reading and closing its body
+// problem details bodies restore the type, title, detail, instance and extension members; plain text bodies become the error message, with any request ID at their end kept in the requestId extension
+func ReadHttpError(response *http.Response) *HttpError {
+	defer response.Body.Close()
+	httpErr := &HttpError{Status: response.StatusCode}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		httpErr.Err = fmt.Errorf("failed to read error response: %w", err)
+		return httpErr
+	}
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	problem := map[string]any{}
+	if (mediaType == problemJsonContentType || mediaType == "application/json") && json.Unmarshal(body, &problem) == nil {
+		httpErr.Type, _ = problem["type"].(string)
+		httpErr.Title, _ = problem["title"].(string)
+		httpErr.Detail, _ = problem["detail"].(string)
+		httpErr.Instance, _ = problem["instance"].(string)
+		for key, value := range problem {
+			switch key {
+			case "type", "title", "status", "detail", "instance":
+			default:
+				if httpErr.Extensions == nil {
+					httpErr.Extensions = map[string]any{}
+				}
+				httpErr.Extensions[key] = value
+			}
+		}
+		if httpErr.Type == "about:blank" {
+			httpErr.Type = ""
+		}
+		if httpErr.Detail != "" {
+			httpErr.Err = errors.New(httpErr.Detail)
+		}
+		return httpErr
+	}
+	message, id, hasID := strings.Cut(string(body), plainTextRequestIDPrefix)
+	if hasID {
+		httpErr.Extensions = map[string]any{"requestId": strings.TrimSpace(id)}
+	}
+	if message = strings.TrimSpace(message); message != "" {
+		httpErr.Err = errors.New(message)
+	}
+	return httpErr
+}
@@ -0,0 +1,101 @@
+package resthelper_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+var (
+	testUpdateItem = resthelper.NewRoute[testBoundRequest, bool]("PUT", "/items/{id:[0-9]+}")
+	testCreateItem = resthelper.NewRoute[testJsonStruct, resthelper.Response[testJsonStruct]]("POST", "/items")
+	testLookupItem = resthelper.NewRoute[testBoundRequest, testJsonStruct]("GET", "/items/{id}")
+)
+
+func TestClientBindsParamsSymmetrically(t *testing.T) {
+	var bound testBoundRequest
+	router := mux.NewRouter()
+	testUpdateItem.Register(router, func(r *http.Request, input testBoundRequest) (bool, *resthelper.HttpError) {
+		bound = input
+		return true, nil
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	verbose := false
+	sent := testBoundRequest{
+		testPaging: testPaging{Limit: 10},
+		ID:         42,
+		Tenant:     "acme",
+		Verbose:    &verbose,
+		Since:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Timeout:    90 * time.Second,
+		Tags:       []string{"a", "b c"},
+		Client:     netip.MustParseAddr("10.0.0.1"),
+		Name:       "Steve",
+	}
+	ok, err := testUpdateItem.Call(context.Background(), resthelper.NewClient(server.URL), sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("expected the response to be decoded")
+	}
+	if !reflect.DeepEqual(bound, sent) {
+		t.Error("expected the server to bind exactly what the client sent\nsent: ", sent, "\nbound:", bound)
+	}
+}
+
+func TestClientResponse(t *testing.T) {
+	router := mux.NewRouter()
+	testCreateItem.Register(router, testCreateHandler)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	response, err := testCreateItem.Call(context.Background(), resthelper.NewClient(server.URL), testJsonStruct{Name: "Steve", Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != http.StatusCreated || response.Headers.Get("Location") == "" || response.Body.Name != "Steve" {
+		t.Error("expected the status, headers and body of the response:", response)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	router := mux.NewRouter()
+	testLookupItem.Register(router, func(r *http.Request, input testBoundRequest) (testJsonStruct, *resthelper.HttpError) {
+		return testProblemHandler(r)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	_, err := testLookupItem.Call(context.Background(), resthelper.NewClient(server.URL), testBoundRequest{ID: 7})
+	var httpErr *resthelper.HttpError
+	if !errors.As(err, &httpErr) {
+		t.Fatal("expected an HttpError, got", err)
+	}
+	if httpErr.Status != http.StatusConflict || httpErr.Type != "https://example.com/probs/taken" || httpErr.Title != "Name taken" || httpErr.Error() != "Steve already exists" {
+		t.Error("expected the server's problem details to be rebuilt:", httpErr)
+	}
+	if httpErr.Instance != "/users/steve" || httpErr.Extensions["name"] != "Steve" {
+		t.Error("expected the instance and extension members to be rebuilt:", httpErr)
+	}
+}
+
+func TestReadHttpErrorPlainText(t *testing.T) {
+	rec := httptest.NewRecorder()
+	http.Error(rec, "no such thing", http.StatusNotFound)
+
+	httpErr := resthelper.ReadHttpError(rec.Result())
+	if httpErr.Status != http.StatusNotFound || httpErr.Error() != "no such thing" {
+		t.Error("expected a plain text error to become the message:", httpErr)
+	}
+}
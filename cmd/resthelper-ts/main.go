@@ -0,0 +1,73 @@
+// Command resthelper-ts generates TypeScript types and a typed fetch client from the OpenAPI document a resthelper service serves
+//
+// Usage:
+//
+//	resthelper-ts -spec http://localhost:8080/openapi.json -out src/api.ts
+//
+// the document is usually served with openapi.Mount, and can also be read from a file written with openapi.Generate
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/preston-wagner/go-resthelper/openapi"
+	"github.com/preston-wagner/go-resthelper/typescript"
+)
+
+func main() {
+	spec := flag.String("spec", "", "the URL or file path of the OpenAPI document")
+	out := flag.String("out", "", "the TypeScript file to write (default stdout)")
+	flag.Parse()
+	if *spec == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	document, err := readDocument(*spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	generated := typescript.Generate(document)
+	if *out == "" {
+		_, err = os.Stdout.Write(generated)
+	} else {
+		err = os.WriteFile(*out, generated, 0o644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func readDocument(spec string) (*openapi.Document, error) {
+	var reader io.ReadCloser
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		response, err := http.Get(spec)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("%s responded with %s", spec, response.Status)
+		}
+		reader = response.Body
+	} else {
+		file, err := os.Open(spec)
+		if err != nil {
+			return nil, err
+		}
+		reader = file
+	}
+	defer reader.Close()
+	document := &openapi.Document{}
+	if err := json.NewDecoder(reader).Decode(document); err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI document from %s: %w", spec, err)
+	}
+	return document, nil
+}
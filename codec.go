@@ -0,0 +1,166 @@
+package resthelper
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Codec marshals and unmarshals request and response bodies for a single media type
+type Codec interface {
+	MediaType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type funcCodec struct {
+	mediaType string
+	marshal   func(any) ([]byte, error)
+	unmarshal func([]byte, any) error
+}
+
+// NewCodec adapts a pair of marshal/unmarshal functions, like those of most MessagePack and CBOR libraries, into a Codec
+func NewCodec(mediaType string, marshal func(any) ([]byte, error), unmarshal func([]byte, any) error) Codec {
+	return &funcCodec{
+		mediaType: mediaType,
+		marshal:   marshal,
+		unmarshal: unmarshal,
+	}
+}
+
+func (codec *funcCodec) MediaType() string {
+	return codec.mediaType
+}
+
+func (codec *funcCodec) Marshal(v any) ([]byte, error) {
+	return codec.marshal(v)
+}
+
+func (codec *funcCodec) Unmarshal(data []byte, v any) error {
+	return codec.unmarshal(data, v)
+}
+
+// JSONCodec is the codec used by default; requests decoded with it honor WithStrictDecoding and the structural Limits
+var JSONCodec = NewCodec("application/json", json.Marshal, json.Unmarshal)
+
+// CodecRegistry holds the codecs a wrapper can choose between, keyed by media type; the first codec registered is the default
+type CodecRegistry struct {
+	lock   sync.RWMutex
+	codecs map[string]Codec
+	order  []string
+}
+
+// NewCodecRegistry creates a registry holding the given codecs, the first of which is used when a request doesn't specify a media type
+func NewCodecRegistry(codecs ...Codec) *CodecRegistry {
+	registry := &CodecRegistry{
+		codecs: map[string]Codec{},
+	}
+	for _, codec := range codecs {
+		registry.Register(codec)
+	}
+	return registry
+}
+
+// DefaultCodecRegistry is used by wrappers without a WithCodecs option; it starts out holding only JSONCodec
+var DefaultCodecRegistry = NewCodecRegistry(JSONCodec)
+
+// WithCodecs makes a wrapper negotiate request and response media types using registry instead of DefaultCodecRegistry
+func WithCodecs(registry *CodecRegistry) Option {
+	return func(cfg *config) {
+		cfg.codecs = registry
+	}
+}
+
+// Register adds codec to the registry, replacing any codec previously registered for the same media type
+func (registry *CodecRegistry) Register(codec Codec) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	mediaType := strings.ToLower(codec.MediaType())
+	if _, ok := registry.codecs[mediaType]; !ok {
+		registry.order = append(registry.order, mediaType)
+	}
+	registry.codecs[mediaType] = codec
+}
+
+// ForContentType returns the codec for a Content-Type header value, treating structured syntax suffixes like application/vnd.api+json as their base type
+func (registry *CodecRegistry) ForContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	if codec, ok := registry.codecs[mediaType]; ok {
+		return codec, true
+	}
+	if _, suffix, ok := strings.Cut(mediaType, "+"); ok {
+		codec, ok := registry.codecs["application/"+suffix]
+		return codec, ok
+	}
+	return nil, false
+}
+
+// ForAccept returns the registered codec an Accept header value prefers, or the default codec if the header is empty
+func (registry *CodecRegistry) ForAccept(accept string) (Codec, bool) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	if len(registry.order) == 0 {
+		return nil, false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return registry.codecs[registry.order[0]], true
+	}
+	ranges := parseAccept(accept)
+	var best Codec
+	bestQ := 0.0
+	for _, mediaType := range registry.order {
+		if q := quality(ranges, mediaType); q > bestQ {
+			best, bestQ = registry.codecs[mediaType], q
+		}
+	}
+	return best, best != nil
+}
+
+func (registry *CodecRegistry) size() int {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	return len(registry.order)
+}
+
+// requestCodec picks the codec for the body of r from its Content-Type
+// bodies without a registered Content-Type are decoded with the default codec, as the wrappers always did, unless the registry holds other codecs to choose between
+// text/plain bodies always get the default codec, since it's what browsers send for fetch calls with a string body
+func (registry *CodecRegistry) requestCodec(r *http.Request) (Codec, *HttpError) {
+	contentType := r.Header.Get("Content-Type")
+	if codec, ok := registry.ForContentType(contentType); ok {
+		return codec, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if contentType != "" && mediaType != "text/plain" && registry.size() > 1 {
+		return nil, NewHttpErrF(http.StatusUnsupportedMediaType, "unsupported Content-Type %q", contentType)
+	}
+	return registry.defaultCodec()
+}
+
+// responseCodec picks the codec for the response to r from its Accept header
+// like requestCodec, it falls back to the default codec when nothing is acceptable, unless the registry holds other codecs to choose between
+func (registry *CodecRegistry) responseCodec(r *http.Request) (Codec, *HttpError) {
+	accept := r.Header.Get("Accept")
+	if codec, ok := registry.ForAccept(accept); ok {
+		return codec, nil
+	}
+	if accept != "" && registry.size() > 1 {
+		return nil, NewHttpErrF(http.StatusNotAcceptable, "none of the accepted media types %q can be produced", accept)
+	}
+	return registry.defaultCodec()
+}
+
+func (registry *CodecRegistry) defaultCodec() (Codec, *HttpError) {
+	codec, ok := registry.ForAccept("")
+	if !ok {
+		return nil, NewHttpErrF(http.StatusInternalServerError, "no codecs registered")
+	}
+	return codec, nil
+}
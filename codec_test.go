@@ -0,0 +1,66 @@
+package resthelper_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func TestCodecNegotiation(t *testing.T) {
+	registry := resthelper.NewCodecRegistry(resthelper.JSONCodec, resthelper.NewCodec("application/xml", xml.Marshal, xml.Unmarshal))
+	handler := resthelper.JsonToJsonWrapper(testJsonHandler, resthelper.WithCodecs(registry))
+
+	cases := []struct {
+		contentType string
+		accept      string
+		body        string
+		status      int
+		response    string
+	}{
+		{"", "", `{"Name":"Steve","Count":7}`, http.StatusOK, "application/json"},
+		{"application/xml", "application/xml", `<testJsonStruct><Name>Steve</Name><Count>7</Count></testJsonStruct>`, http.StatusOK, "application/xml"},
+		{"application/vnd.example+json", "text/html, application/*;q=0.5", `{"Name":"Steve","Count":7}`, http.StatusOK, "application/json"},
+		{"application/json", "application/xml;q=0.5, application/json;q=0.9", `{"Name":"Steve","Count":7}`, http.StatusOK, "application/json"},
+		{"application/json", "text/csv", `{"Name":"Steve","Count":7}`, http.StatusNotAcceptable, "text/plain"},
+		{"text/plain;charset=UTF-8", "", `{"Name":"Steve","Count":7}`, http.StatusOK, "application/json"},
+		{"text/csv", "", `Steve,7`, http.StatusUnsupportedMediaType, "text/plain"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(c.body))
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != c.status {
+			t.Error("Content-Type", c.contentType, "Accept", c.accept, "expected", c.status, "got", rec.Code, rec.Body.String())
+		}
+		if contentType := rec.Header().Get("Content-Type"); contentType != c.response {
+			t.Error("Content-Type", c.contentType, "Accept", c.accept, "expected response type", c.response, "got", contentType)
+		}
+		if c.status == http.StatusOK && !strings.Contains(rec.Body.String(), "Steve") {
+			t.Error("Content-Type", c.contentType, "Accept", c.accept, "body did not survive round trip:", rec.Body.String())
+		}
+	}
+}
+
+func TestDefaultCodecFallback(t *testing.T) {
+	handler := resthelper.JsonToJsonWrapper(testJsonHandler)
+	for _, contentType := range []string{"text/plain;charset=UTF-8", "application/x-www-form-urlencoded"} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"Name":"Steve","Count":7}`))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", "text/plain")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" || !strings.Contains(rec.Body.String(), "Steve") {
+			t.Error("Content-Type", contentType, "expected a json response, got", rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package resthelper
+
+import (
+	"context"
+	"net/http"
+)
+
+// ContextKey stores and retrieves request-scoped values of a single type, so handlers never need unchecked type assertions
+// keys are compared by identity; create each one once with NewContextKey, typically as a package level variable
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey creates a key for values of type T; name is only used for debugging
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+func (key *ContextKey[T]) String() string {
+	return "resthelper.ContextKey(" + key.name + ")"
+}
+
+// WithValue returns a copy of ctx carrying value under key
+func (key *ContextKey[T]) WithValue(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, key, value)
+}
+
+// WithRequestValue returns a shallow copy of r whose context carries value under key; it is meant to be returned from a ContextHook
+func (key *ContextKey[T]) WithRequestValue(r *http.Request, value T) *http.Request {
+	return r.WithContext(key.WithValue(r.Context(), value))
+}
+
+// Value returns the value stored under key in ctx, and whether there was one
+func (key *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	value, ok := ctx.Value(key).(T)
+	return value, ok
+}
+
+// RequestValue returns the value stored under key in the context of r, and whether there was one
+func (key *ContextKey[T]) RequestValue(r *http.Request) (T, bool) {
+	return key.Value(r.Context())
+}
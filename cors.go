@@ -0,0 +1,118 @@
+package resthelper
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy controls the Access-Control-* headers a wrapped handler responds with
+type CORSPolicy struct {
+	// AllowedOrigins may contain exact origins like "https://example.com", patterns with a single wildcard like "https://*.example.com", or "*" to allow any origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache preflight results; zero omits the header
+	MaxAge time.Duration
+}
+
+// DefaultCORSPolicy allows any origin without credentials, matching the headers resthelper has always sent
+func DefaultCORSPolicy() *CORSPolicy {
+	return &CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"POST", "GET", "OPTIONS", "PUT", "DELETE"},
+		AllowedHeaders: []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
+	}
+}
+
+// WithCORS replaces the default CORS policy for a handler
+func WithCORS(policy CORSPolicy) Option {
+	return func(cfg *config) {
+		cfg.cors = &policy
+	}
+}
+
+// WithoutCORS disables CORS headers entirely for a handler, so browsers will refuse cross-origin calls to it
+func WithoutCORS() Option {
+	return func(cfg *config) {
+		cfg.cors = nil
+	}
+}
+
+func (policy *CORSPolicy) allowsAnyOrigin() bool {
+	for _, allowed := range policy.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOrigin reports whether origin matches any of the policy's allowed origins or origin patterns
+func (policy *CORSPolicy) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range policy.AllowedOrigins {
+		if originMatches(strings.ToLower(allowed), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func originMatches(pattern, origin string) bool {
+	if pattern == "*" {
+		return true
+	}
+	prefix, suffix, isPattern := strings.Cut(pattern, "*")
+	if !isPattern {
+		return pattern == origin
+	}
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+// writeHeaders sets the CORS headers for a response to r, exposing alsoExposed (such as the request ID header) along with the policy's ExposedHeaders; a nil policy writes nothing
+func (policy *CORSPolicy) writeHeaders(w http.ResponseWriter, r *http.Request, alsoExposed ...string) {
+	if policy == nil {
+		return
+	}
+	origin := r.Header.Get("Origin")
+	if policy.allowsAnyOrigin() && !policy.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		// the response depends on the Origin header, so caches must not share it between origins
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !policy.AllowsOrigin(origin) {
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if policy.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+	if len(policy.AllowedMethods) > 0 {
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+	}
+	if len(policy.AllowedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+	}
+	exposed := slices.Clip(policy.ExposedHeaders)
+	for _, header := range alsoExposed {
+		if header != "" && !slices.ContainsFunc(exposed, func(existing string) bool { return strings.EqualFold(existing, header) }) {
+			exposed = append(exposed, header)
+		}
+	}
+	if len(exposed) > 0 {
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(exposed, ", "))
+	}
+	if policy.MaxAge > 0 && isPreflight(r) {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+	}
+}
+
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
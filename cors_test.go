@@ -0,0 +1,92 @@
+package resthelper_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func testCorsHandler(r *http.Request) *resthelper.HttpError {
+	return nil
+}
+
+func corsResponse(handler func(http.ResponseWriter, *http.Request), method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/cors/", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestDefaultCORSPolicy(t *testing.T) {
+	rec := corsResponse(resthelper.NoContentWrapper(testCorsHandler), "GET", "https://anywhere.example")
+	if allowOrigin := rec.Header().Get("Access-Control-Allow-Origin"); allowOrigin != "*" {
+		t.Error("expected wildcard origin, got", allowOrigin)
+	}
+	if allowMethods := rec.Header().Get("Access-Control-Allow-Methods"); allowMethods != "POST, GET, OPTIONS, PUT, DELETE" {
+		t.Error("unexpected Access-Control-Allow-Methods:", allowMethods)
+	}
+}
+
+func TestCORSPolicyOriginReflection(t *testing.T) {
+	handler := resthelper.NoContentWrapper(testCorsHandler, resthelper.WithCORS(resthelper.CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowedMethods:   []string{"GET", "PATCH"},
+		ExposedHeaders:   []string{"Location"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+
+	for _, origin := range []string{"https://app.example.com", "https://pr-12.preview.example.com"} {
+		rec := corsResponse(handler, "GET", origin)
+		if allowOrigin := rec.Header().Get("Access-Control-Allow-Origin"); allowOrigin != origin {
+			t.Error("expected origin", origin, "to be reflected, got", allowOrigin)
+		}
+		if rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Error("expected credentials to be allowed for", origin)
+		}
+		if rec.Header().Get("Access-Control-Expose-Headers") != "Location, X-Request-ID" {
+			t.Error("expected exposed headers for", origin)
+		}
+		if rec.Header().Get("Vary") != "Origin" {
+			t.Error("expected Vary: Origin for", origin)
+		}
+		if rec.Header().Get("Access-Control-Max-Age") != "" {
+			t.Error("max age should only be sent in response to preflight requests")
+		}
+	}
+
+	for _, origin := range []string{"https://evil.example.com", "https://preview.example.com", ""} {
+		rec := corsResponse(handler, "GET", origin)
+		if allowOrigin := rec.Header().Get("Access-Control-Allow-Origin"); allowOrigin != "" {
+			t.Error("origin", origin, "should not be allowed, got", allowOrigin)
+		}
+		if rec.Header().Get("Vary") != "Origin" {
+			t.Error("expected Vary: Origin for", origin)
+		}
+	}
+
+	preflight := httptest.NewRequest("OPTIONS", "/cors/", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", "PATCH")
+	rec := httptest.NewRecorder()
+	handler(rec, preflight)
+	if maxAge := rec.Header().Get("Access-Control-Max-Age"); maxAge != "600" {
+		t.Error("expected max age of 600 seconds, got", maxAge)
+	}
+}
+
+func TestWithoutCORS(t *testing.T) {
+	rec := corsResponse(resthelper.NoContentWrapper(testCorsHandler, resthelper.WithoutCORS()), "GET", "https://app.example.com")
+	for key := range rec.Header() {
+		if strings.HasPrefix(key, "Access-Control-") {
+			t.Error("expected no CORS headers, got", key)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package resthelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// WithStrictDecoding makes DecodeRequest reject unknown fields, data following the json value and duplicate object keys, instead of silently ignoring them
+// pass it to SetDefaultOptions to make every wrapper strict, and WithStrictDecoding(false) to relax individual routes
+func WithStrictDecoding(strict bool) Option {
+	return func(cfg *config) {
+		cfg.strictDecoding = strict
+	}
+}
+
+// decodeBody reads a single value from body into target with codec, returning io.EOF untouched if the body is empty
+func decodeBody(body io.Reader, target any, codec Codec, cfg *config) error {
+	if codec == JSONCodec {
+		return decodeJson(body, target, cfg)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return io.EOF
+	}
+	return codec.Unmarshal(data, target)
+}
+
+// decodeJson reads a single json value from body into target, returning io.EOF untouched if the body is empty
+func decodeJson(body io.Reader, target any, cfg *config) error {
+	if !cfg.strictDecoding && !cfg.limits.checksStructure() {
+		return json.NewDecoder(body).Decode(target)
+	}
+	// strictness and structural limits both need to look at the whole document before it is decoded
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+	if cfg.limits.checksStructure() {
+		if err := scanJson(data, cfg.limits); err != nil {
+			return err
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(target); err != nil || !cfg.strictDecoding {
+		return err
+	}
+	offset := decoder.InputOffset()
+	if _, err := decoder.Token(); err != io.EOF {
+		return decodeHttpErr(fmt.Errorf("unexpected data after json value at byte offset %d", offset), "", offset)
+	}
+	checker := strictChecker{data: data, decoder: json.NewDecoder(bytes.NewReader(data))}
+	return checker.value(reflect.TypeOf(target))
+}
+
+// strictChecker walks a valid json document alongside the type it was decoded into, finding the keys encoding/json would have ignored: unknown struct fields and repeated keys
+type strictChecker struct {
+	data    []byte
+	decoder *json.Decoder
+}
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// value checks the next json value, which was decoded into a value of type t; a nil t accepts anything, as for interfaces, maps of them and types decoding themselves
+func (checker *strictChecker) value(t reflect.Type) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		t = nil
+	}
+	token, err := checker.decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		keys := map[string]bool{}
+		for checker.decoder.More() {
+			offset := skipSeparators(checker.data, checker.decoder.InputOffset())
+			token, err := checker.decoder.Token()
+			if err != nil {
+				return err
+			}
+			key := token.(string)
+			if keys[key] {
+				return decodeHttpErr(fmt.Errorf("duplicate key %q at byte offset %d", key, offset), key, offset)
+			}
+			keys[key] = true
+			memberType, known := objectMemberType(t, key)
+			if !known {
+				return decodeHttpErr(fmt.Errorf("unknown field %q at byte offset %d", key, offset), key, offset)
+			}
+			if err := checker.value(memberType); err != nil {
+				return err
+			}
+		}
+		_, err = checker.decoder.Token()
+	case json.Delim('['):
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+		for checker.decoder.More() {
+			if err := checker.value(elemType); err != nil {
+				return err
+			}
+		}
+		_, err = checker.decoder.Token()
+	}
+	return err
+}
+
+// objectMemberType returns the type the value of key in a json object decodes into, and whether encoding/json would use it at all
+func objectMemberType(t reflect.Type, key string) (reflect.Type, bool) {
+	if t == nil {
+		return nil, true
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem(), true
+	case reflect.Struct:
+		fields := jsonFields(t)
+		for _, field := range fields {
+			if field.name == key {
+				return field.typ, true
+			}
+		}
+		// encoding/json falls back to a case insensitive match
+		for _, field := range fields {
+			if strings.EqualFold(field.name, key) {
+				return field.typ, true
+			}
+		}
+		return nil, false
+	default:
+		return nil, true
+	}
+}
+
+// jsonField is a struct field as encoding/json sees it, possibly promoted from an embedded struct
+type jsonField struct {
+	name   string
+	typ    reflect.Type
+	index  []int
+	tagged bool
+}
+
+var jsonFieldsCache sync.Map // map[reflect.Type][]jsonField
+
+// jsonFields lists the fields encoding/json decodes struct type t's members into, in field order
+// like encoding/json, it resolves names shared between embedded structs by depth: a shallower field hides deeper ones, a tagged field wins at the same depth, and otherwise the name is ambiguous and ignored
+func jsonFields(t reflect.Type) []jsonField {
+	if cached, ok := jsonFieldsCache.Load(t); ok {
+		return cached.([]jsonField)
+	}
+	type level struct {
+		typ        reflect.Type
+		index      []int
+		duplicated bool // embedded more than once at the same depth, making all of its fields ambiguous
+	}
+	candidates := []jsonField{}
+	visited := map[reflect.Type]bool{}
+	for next := []level{{typ: t}}; len(next) > 0; {
+		current := next
+		next = nil
+		for _, embedding := range current {
+			if visited[embedding.typ] {
+				continue
+			}
+			visited[embedding.typ] = true
+			for i := range embedding.typ.NumField() {
+				field := embedding.typ.Field(i)
+				fieldType := field.Type
+				if fieldType.Name() == "" && fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+				if field.Anonymous {
+					// unexported embedded structs still promote their exported fields
+					if !field.IsExported() && fieldType.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !field.IsExported() {
+					continue
+				}
+				tag := field.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, _, _ := strings.Cut(tag, ",")
+				index := append(slices.Clone(embedding.index), i)
+				if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+					if queued := slices.IndexFunc(next, func(l level) bool { return l.typ == fieldType }); queued >= 0 {
+						next[queued].duplicated = true
+					} else {
+						next = append(next, level{typ: fieldType, index: index})
+					}
+					continue
+				}
+				tagged := name != ""
+				if !tagged {
+					name = field.Name
+				}
+				candidate := jsonField{name: name, typ: field.Type, index: index, tagged: tagged}
+				candidates = append(candidates, candidate)
+				if embedding.duplicated {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b jsonField) int {
+		if a.name != b.name {
+			return strings.Compare(a.name, b.name)
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) - len(b.index)
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.index, b.index)
+	})
+	fields := []jsonField{}
+	for i := 0; i < len(candidates); {
+		j := i + 1
+		for j < len(candidates) && candidates[j].name == candidates[i].name {
+			j++
+		}
+		dominant := candidates[i]
+		if j-i == 1 || len(candidates[i+1].index) > len(dominant.index) || (dominant.tagged && !candidates[i+1].tagged) {
+			fields = append(fields, dominant)
+		}
+		i = j
+	}
+	slices.SortFunc(fields, func(a, b jsonField) int {
+		return slices.Compare(a.index, b.index)
+	})
+
+	cached, _ := jsonFieldsCache.LoadOrStore(t, fields)
+	return cached.([]jsonField)
+}
+
+// scanJson walks the tokens of the first json value in data, enforcing the structural limits
+func scanJson(data []byte, limits Limits) error {
+	type frame struct {
+		object    bool
+		expectKey bool
+		elements  int
+	}
+	stack := []*frame{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := skipSeparators(data, decoder.InputOffset())
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if top != nil && (!top.object || top.expectKey) && token != json.Delim('}') && token != json.Delim(']') {
+			// a new array element or object key
+			top.elements++
+			if limits.MaxElements > 0 && top.elements > limits.MaxElements {
+				return decodeHttpErr(fmt.Errorf("more than %d elements in the array or object at byte offset %d", limits.MaxElements, offset), "", offset)
+			}
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			if limits.MaxDepth > 0 && len(stack) >= limits.MaxDepth {
+				return decodeHttpErr(fmt.Errorf("json nested more than %d levels deep at byte offset %d", limits.MaxDepth, offset), "", offset)
+			}
+			if token == json.Delim('{') {
+				stack = append(stack, &frame{object: true, expectKey: true})
+			} else {
+				stack = append(stack, &frame{})
+			}
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				top = stack[len(stack)-1]
+			} else {
+				top = nil
+			}
+		default:
+			if top != nil && top.object && top.expectKey {
+				top.expectKey = false
+				continue
+			}
+		}
+		if top == nil {
+			// the top level value is complete; anything after it is the decoder's business
+			return nil
+		}
+		// a value just ended, so an enclosing object expects its next key
+		if top.object {
+			top.expectKey = true
+		}
+	}
+}
+
+// skipSeparators advances offset past the whitespace, commas and colons json.Decoder.Token skips before its next token
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func decodeHttpErr(err error, field string, offset int64) *HttpError {
+	httpErr := NewHttpErr(http.StatusBadRequest, err)
+	httpErr.Extensions = map[string]any{"offset": offset}
+	if field != "" {
+		httpErr.Extensions["field"] = field
+	}
+	return httpErr
+}
+
+// decodeError turns an error from decodeJson into a 400, keeping the field and byte offset of standard library json errors where available
+func decodeError(err error) *HttpError {
+	var httpErr *HttpError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return NewHttpErrF(http.StatusRequestEntityTooLarge, "request body larger than %d bytes", maxBytesErr.Limit)
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return decodeHttpErr(fmt.Errorf("%w at byte offset %d", err, syntaxErr.Offset), "", syntaxErr.Offset)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return decodeHttpErr(err, typeErr.Field, typeErr.Offset)
+	}
+	return NewHttpErr(http.StatusBadRequest, err)
+}
@@ -0,0 +1,124 @@
+package resthelper_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func decodeProblem(t *testing.T, handler func(http.ResponseWriter, *http.Request), body string) (int, map[string]any) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Accept", "application/problem+json")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	problem := map[string]any{}
+	if rec.Code != http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err, rec.Body.String())
+		}
+	}
+	return rec.Code, problem
+}
+
+func TestStrictDecoding(t *testing.T) {
+	lenient := resthelper.JsonToJsonWrapper(testJsonHandler)
+	strict := resthelper.JsonToJsonWrapper(testJsonHandler, resthelper.WithStrictDecoding(true))
+
+	cases := []struct {
+		body   string
+		field  string
+		offset float64
+	}{
+		{`{"Name":"Steve","Cuont":7}`, "Cuont", 16},
+		{`{"Name":"Steve"}garbage`, "", 16},
+		{`{"Name":"Steve","Count":1,"Name":"Bob"}`, "Name", 26},
+	}
+	for _, c := range cases {
+		if status, _ := decodeProblem(t, lenient, c.body); status != http.StatusOK {
+			t.Error("lenient decoding should accept", c.body, "got", status)
+		}
+		status, problem := decodeProblem(t, strict, c.body)
+		if status != http.StatusBadRequest {
+			t.Error("strict decoding should reject", c.body, "got", status)
+			continue
+		}
+		if c.field != "" && problem["field"] != c.field {
+			t.Error("expected field", c.field, "for", c.body, "got", problem["field"])
+		}
+		if problem["offset"] != c.offset {
+			t.Error("expected offset", c.offset, "for", c.body, "got", problem["offset"])
+		}
+	}
+
+	// keys only need to be unique within their own object
+	if status, problem := decodeProblem(t, strict, `{"Name":"Steve","Count":1} `); status != http.StatusOK {
+		t.Error("strict decoding rejected a valid body:", problem)
+	}
+}
+
+func TestStrictDecodingDefault(t *testing.T) {
+	resthelper.SetDefaultOptions(resthelper.WithStrictDecoding(true))
+	defer resthelper.SetDefaultOptions()
+
+	strict := resthelper.JsonToJsonWrapper(testJsonHandler)
+	relaxed := resthelper.JsonToJsonWrapper(testJsonHandler, resthelper.WithStrictDecoding(false))
+	if status, _ := decodeProblem(t, strict, `{"Cuont":7}`); status != http.StatusBadRequest {
+		t.Error("expected global strict decoding, got", status)
+	}
+	if status, _ := decodeProblem(t, relaxed, `{"Cuont":7}`); status != http.StatusOK {
+		t.Error("expected per wrapper override of strict decoding, got", status)
+	}
+}
+
+type testNestedStruct struct {
+	Items []testJsonStruct
+	Meta  map[string]any
+	Raw   json.RawMessage
+}
+
+type testEmbeddedMeta struct {
+	Meta   testJsonStruct
+	Note   string
+	Shared int
+}
+
+type testOtherEmbedded struct {
+	Shared int
+}
+
+// testShadowingStruct's own Meta hides the promoted one, and Shared is ambiguous between its two embedded structs
+type testShadowingStruct struct {
+	testEmbeddedMeta
+	testOtherEmbedded
+	Meta map[string]any
+}
+
+func TestStrictDecodingNested(t *testing.T) {
+	strict := resthelper.JsonToJsonWrapper(func(r *http.Request, input testNestedStruct) (testNestedStruct, *resthelper.HttpError) {
+		return input, nil
+	}, resthelper.WithStrictDecoding(true))
+
+	valid := `{"Items":[{"name":"a","Count":1}],"Meta":{"anything":{"goes":1}},"Raw":{"x":1,"y":2}}`
+	if status, problem := decodeProblem(t, strict, valid); status != http.StatusOK {
+		t.Error("strict decoding rejected a valid body:", problem)
+	}
+	// Items is a known field of the outer object, but not of the elements
+	status, problem := decodeProblem(t, strict, `{"Items":[{"Name":"a"},{"Name":"b","Items":[]}]}`)
+	if status != http.StatusBadRequest || problem["field"] != "Items" || problem["offset"] != float64(35) {
+		t.Error("expected the unknown field in the second element, got", status, problem)
+	}
+
+	shadowing := resthelper.JsonToJsonWrapper(func(r *http.Request, input testShadowingStruct) (testShadowingStruct, *resthelper.HttpError) {
+		return input, nil
+	}, resthelper.WithStrictDecoding(true))
+	if status, problem := decodeProblem(t, shadowing, `{"Meta":{"x":1},"Note":"promoted"}`); status != http.StatusOK {
+		t.Error("expected the outer Meta to hide the embedded one, got", status, problem)
+	}
+	if status, problem := decodeProblem(t, shadowing, `{"Shared":1}`); status != http.StatusBadRequest || problem["field"] != "Shared" {
+		t.Error("expected a field ambiguous between embedded structs to be unknown, got", status, problem)
+	}
+}
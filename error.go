@@ -1,16 +1,35 @@
 package resthelper
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 )
 
+// HttpError carries the status code a wrapper should respond with, along with the optional members of an RFC 7807 problem details object
+// clients that accept application/problem+json receive those members as json; everyone else receives Error() as plain text
 type HttpError struct {
 	Err    error
 	Status int
+
+	Type       string         // a URI reference identifying the problem type; "about:blank" if empty
+	Title      string         // a short summary of the problem type; defaults to the status text
+	Detail     string         // an explanation specific to this occurrence; defaults to Err.Error()
+	Instance   string         // a URI reference identifying this specific occurrence
+	Extensions map[string]any // additional members, serialized alongside the standard ones
 }
 
 func (e HttpError) Error() string {
-	return e.Err.Error()
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Detail != "" {
+		return e.Detail
+	}
+	if e.Title != "" {
+		return e.Title
+	}
+	return http.StatusText(e.Status)
 }
 
 func (e HttpError) Unwrap() error {
@@ -30,3 +49,44 @@ func NewHttpErrF(status int, format string, a ...any) *HttpError {
 		Err:    fmt.Errorf(format, a...),
 	}
 }
+
+// NewProblem creates an HttpError with the RFC 7807 type, title and detail members set
+func NewProblem(status int, problemType, title, detail string) *HttpError {
+	return &HttpError{
+		Err:    errors.New(detail),
+		Status: status,
+		Type:   problemType,
+		Title:  title,
+		Detail: detail,
+	}
+}
+
+// Problem returns the RFC 7807 representation of the error, with defaults filled in for any unset standard members
+func (e HttpError) Problem() map[string]any {
+	problem := make(map[string]any, len(e.Extensions)+5)
+	for key, value := range e.Extensions {
+		problem[key] = value
+	}
+	problemType := e.Type
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+	title := e.Title
+	if title == "" {
+		title = http.StatusText(e.Status)
+	}
+	detail := e.Detail
+	if detail == "" && e.Err != nil {
+		detail = e.Err.Error()
+	}
+	problem["type"] = problemType
+	problem["title"] = title
+	problem["status"] = e.Status
+	if detail != "" {
+		problem["detail"] = detail
+	}
+	if e.Instance != "" {
+		problem["instance"] = e.Instance
+	}
+	return problem
+}
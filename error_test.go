@@ -0,0 +1,89 @@
+package resthelper_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func testProblemHandler(r *http.Request) (testJsonStruct, *resthelper.HttpError) {
+	httpErr := resthelper.NewProblem(http.StatusConflict, "https://example.com/probs/taken", "Name taken", "Steve already exists")
+	httpErr.Instance = "/users/steve"
+	httpErr.Extensions = map[string]any{"name": "Steve"}
+	return testJsonStruct{}, httpErr
+}
+
+func TestProblemJsonErrors(t *testing.T) {
+	handler := resthelper.JsonResponseWrapper(testProblemHandler)
+
+	req := httptest.NewRequest("GET", "/users/steve", nil)
+	req.Header.Set("Accept", "application/problem+json, text/plain;q=0.5")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Error("expected status", http.StatusConflict, "got", rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Error("expected application/problem+json, got", contentType)
+	}
+	problem := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"type":     "https://example.com/probs/taken",
+		"title":    "Name taken",
+		"status":   float64(http.StatusConflict),
+		"detail":   "Steve already exists",
+		"instance": "/users/steve",
+		"name":     "Steve",
+	}
+	for key, value := range expected {
+		if problem[key] != value {
+			t.Error("expected problem member", key, "to be", value, "got", problem[key])
+		}
+	}
+}
+
+func TestProblemJsonDefaults(t *testing.T) {
+	handler := resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		return resthelper.NewHttpErrF(http.StatusNotFound, "no such thing")
+	})
+
+	req := httptest.NewRequest("DELETE", "/things/1", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	problem := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem["type"] != "about:blank" || problem["title"] != "Not Found" || problem["detail"] != "no such thing" {
+		t.Error("problem defaults not filled in:", problem)
+	}
+}
+
+func TestPlainTextErrorFallback(t *testing.T) {
+	handler := resthelper.JsonResponseWrapper(testProblemHandler)
+
+	for _, accept := range []string{"", "*/*", "text/plain", "application/json;q=0.1, text/plain"} {
+		req := httptest.NewRequest("GET", "/users/steve", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		if contentType := rec.Header().Get("Content-Type"); contentType != "text/plain" {
+			t.Error("Accept", accept, "expected text/plain, got", contentType)
+		}
+		if body := plainTextError(t, rec); body != "Steve already exists" {
+			t.Error("Accept", accept, "unexpected body:", body)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package resthelper
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/unicycle/slices_ext"
+)
+
+// GroupConfig holds the hooks and options shared by every route in a Group
+type GroupConfig struct {
+	PreRequestHooks   []PreRequestHook
+	PostResponseHooks []PostResponseHook
+	// Options apply to every route, e.g. WithCORS or WithCodecs
+	Options []Option
+}
+
+// Group registers routes under a common path prefix, wrapping each with the group's hooks and options so they don't have to be repeated for every route
+type Group struct {
+	router *mux.Router
+	table  *routeTable
+	config GroupConfig
+}
+
+// NewGroup creates a Group for the routes of router under prefix (which may be empty); routes registered through it, and through any nested groups, are listed by Routes(router)
+func NewGroup(router *mux.Router, prefix string, config GroupConfig) *Group {
+	return newGroup(router, tableFor(router), prefix, config)
+}
+
+func newGroup(router *mux.Router, table *routeTable, prefix string, config GroupConfig) *Group {
+	if prefix != "" {
+		router = router.PathPrefix(prefix).Subrouter()
+	}
+	return &Group{
+		router: router,
+		table:  table,
+		config: config,
+	}
+}
+
+// Group creates a nested group under prefix, inheriting this group's configuration and extending it with config
+// the parent's hooks run before the child's, and the child's options are applied after (and so override) the parent's
+func (g *Group) Group(prefix string, config GroupConfig) *Group {
+	return newGroup(g.router, g.table, prefix, GroupConfig{
+		PreRequestHooks:   slices_ext.Concatenate(g.config.PreRequestHooks, config.PreRequestHooks),
+		PostResponseHooks: slices_ext.Concatenate(g.config.PostResponseHooks, config.PostResponseHooks),
+		Options:           slices_ext.Concatenate(g.config.Options, config.Options),
+	})
+}
+
+// Router returns the group's router, for registering routes the group can't express
+func (g *Group) Router() *mux.Router {
+	return g.router
+}
+
+// Handle registers endpoint for method requests to path, relative to the group's prefix, and records it in the route table
+func (g *Group) Handle(method, path string, endpoint Endpoint, opts ...RouteOption) *mux.Route {
+	info := RouteInfo{
+		Method:            method,
+		RequestType:       endpoint.requestType,
+		ResponseType:      endpoint.responseType,
+		PreRequestHooks:   g.config.PreRequestHooks,
+		PostResponseHooks: g.config.PostResponseHooks,
+		Options:           g.config.Options,
+	}
+	for _, opt := range opts {
+		opt(&info)
+	}
+	handler := endpoint.build(info.PreRequestHooks, info.PostResponseHooks, info.Options)
+	info.Route = g.router.Handle(path, handler).Methods(method)
+	info.Path, _ = info.Route.GetPathTemplate()
+	g.table.add(info)
+	return info.Route
+}
+
+func (g *Group) Get(path string, endpoint Endpoint, opts ...RouteOption) *mux.Route {
+	return g.Handle(http.MethodGet, path, endpoint, opts...)
+}
+
+func (g *Group) Post(path string, endpoint Endpoint, opts ...RouteOption) *mux.Route {
+	return g.Handle(http.MethodPost, path, endpoint, opts...)
+}
+
+func (g *Group) Put(path string, endpoint Endpoint, opts ...RouteOption) *mux.Route {
+	return g.Handle(http.MethodPut, path, endpoint, opts...)
+}
+
+func (g *Group) Patch(path string, endpoint Endpoint, opts ...RouteOption) *mux.Route {
+	return g.Handle(http.MethodPatch, path, endpoint, opts...)
+}
+
+func (g *Group) Delete(path string, endpoint Endpoint, opts ...RouteOption) *mux.Route {
+	return g.Handle(http.MethodDelete, path, endpoint, opts...)
+}
+
+// Endpoint is a typed handler waiting to be registered on a Group, which supplies its hooks and options
+type Endpoint struct {
+	requestType  reflect.Type // nil if the handler doesn't read a request body
+	responseType reflect.Type // nil if the handler responds without a body
+	build        func(preRequestHooks []PreRequestHook, postResponseHooks []PostResponseHook, opts []Option) *wrappedHandler
+}
+
+// JsonToJson creates an Endpoint that decodes the request body and responds with json, like JsonToJsonWrapper
+func JsonToJson[REQUEST_TYPE any, RESPONSE_TYPE any](handler JsonRequestHandler[REQUEST_TYPE, RESPONSE_TYPE]) Endpoint {
+	return Endpoint{
+		requestType:  reflect.TypeFor[REQUEST_TYPE](),
+		responseType: responseBodyType[RESPONSE_TYPE](),
+		build: func(preRequestHooks []PreRequestHook, postResponseHooks []PostResponseHook, opts []Option) *wrappedHandler {
+			return newJsonResponseHandler(preRequestHooks, JsonRequestWrapper(handler), postResponseHooks, opts)
+		},
+	}
+}
+
+// JsonResponse creates an Endpoint that responds with json, like JsonResponseWrapper
+func JsonResponse[T any](handler JsonResponseHandler[T]) Endpoint {
+	return Endpoint{
+		responseType: responseBodyType[T](),
+		build: func(preRequestHooks []PreRequestHook, postResponseHooks []PostResponseHook, opts []Option) *wrappedHandler {
+			return newJsonResponseHandler(preRequestHooks, handler, postResponseHooks, opts)
+		},
+	}
+}
+
+// NoContent creates an Endpoint that responds with 204 No Content, like NoContentWrapper
+func NoContent(handler NoResponseHandler) Endpoint {
+	return Endpoint{
+		build: func(preRequestHooks []PreRequestHook, postResponseHooks []PostResponseHook, opts []Option) *wrappedHandler {
+			return newNoContentHandler(preRequestHooks, handler, postResponseHooks, opts)
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package resthelper_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func TestGroup(t *testing.T) {
+	calls := []string{}
+	hook := func(name string) resthelper.PreRequestHook {
+		return func(r *http.Request) *resthelper.HttpError {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	router := mux.NewRouter()
+	api := resthelper.NewGroup(router, "/api", resthelper.GroupConfig{
+		PreRequestHooks: []resthelper.PreRequestHook{hook("api")},
+		Options: []resthelper.Option{resthelper.WithCORS(resthelper.CORSPolicy{
+			AllowedOrigins: []string{"https://app.example.com"},
+		})},
+	})
+	admin := api.Group("/admin", resthelper.GroupConfig{
+		PreRequestHooks: []resthelper.PreRequestHook{resthelper.Enrich(testAuthHook), testAdminHook, hook("admin")},
+		Options:         []resthelper.Option{resthelper.WithoutCORS()},
+	})
+
+	api.Post("/things", resthelper.JsonToJson(testJsonHandler))
+	api.Get("/things/{id}", resthelper.JsonResponse(func(r *http.Request) (string, *resthelper.HttpError) {
+		return mux.Vars(r)["id"], nil
+	}))
+	admin.Delete("/things/{id}", resthelper.NoContent(testCorsHandler))
+
+	serve := func(method, path, body, authorization string) *httptest.ResponseRecorder {
+		calls = []string{}
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Authorization", authorization)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("POST", "/api/things", `{"Name":"Steve","Count":7}`, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Error("unexpected response from group route:", rec.Code, rec.Header())
+	}
+	if len(calls) != 1 || calls[0] != "api" {
+		t.Error("expected group hook to run, got", calls)
+	}
+
+	rec = serve("GET", "/api/things/7", "", "")
+	if rec.Code != http.StatusOK || rec.Body.String() != `"7"` {
+		t.Error("unexpected response from group route:", rec.Code, rec.Body.String())
+	}
+
+	rec = serve("DELETE", "/api/admin/things/7", "", "Bearer admin")
+	if rec.Code != http.StatusNoContent {
+		t.Error("expected", http.StatusNoContent, "got", rec.Code)
+	}
+	if len(calls) != 2 || calls[0] != "api" || calls[1] != "admin" {
+		t.Error("expected parent hooks to run before child hooks, got", calls)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("expected nested group to override the parent's CORS policy")
+	}
+
+	if rec = serve("DELETE", "/api/admin/things/7", "", "Bearer user"); rec.Code != http.StatusForbidden {
+		t.Error("expected", http.StatusForbidden, "got", rec.Code)
+	}
+	if rec = serve("DELETE", "/api/things/7", "", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Error("expected", http.StatusMethodNotAllowed, "got", rec.Code)
+	}
+}
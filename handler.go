@@ -0,0 +1,125 @@
+package resthelper
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// wrappedHandler is what every wrapper builds; keeping the config alongside the serving function lets helpers like HandlePreflight recover it from a registered route
+type wrappedHandler struct {
+	cfg   *config
+	serve func(http.ResponseWriter, *http.Request)
+}
+
+func newWrappedHandler(cfg *config, serve func(http.ResponseWriter, *http.Request)) *wrappedHandler {
+	return &wrappedHandler{
+		cfg:   cfg,
+		serve: serve,
+	}
+}
+
+// handleFunc is what the wrappers return, for registering with router.HandleFunc; given a handlerProbe instead of a ResponseWriter, it hands back its wrappedHandler rather than serving
+func (handler *wrappedHandler) handleFunc(w http.ResponseWriter, r *http.Request) {
+	if probe, ok := w.(*handlerProbe); ok {
+		probe.handler = handler
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+type handlerProbe struct {
+	http.ResponseWriter
+	handler *wrappedHandler
+}
+
+// every method value of wrappedHandler.handleFunc shares the same code pointer, which is how funcs returned by the wrappers are told apart from other handlers before probing them
+var handleFuncCode = reflect.ValueOf((&wrappedHandler{}).handleFunc).Pointer()
+
+// wrappedHandlerOf returns the wrappedHandler behind a route's handler, whether it was registered as an http.Handler or with HandleFunc, or nil if it was built some other way
+func wrappedHandlerOf(handler http.Handler) *wrappedHandler {
+	switch handler := handler.(type) {
+	case *wrappedHandler:
+		return handler
+	case http.HandlerFunc:
+		if reflect.ValueOf(handler).Pointer() != handleFuncCode {
+			return nil
+		}
+		probe := &handlerProbe{}
+		handler(probe, nil)
+		return probe.handler
+	}
+	return nil
+}
+
+func (handler *wrappedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if handler.cfg.cors != nil && isPreflight(r) {
+		respondToPreflight(w, r, handler.cfg.cors, nil)
+		return
+	}
+	rec := &responseRecorder{ResponseWriter: w, request: r, span: noopSpan{}}
+	defer handler.finish(rec, time.Now())
+	handler.cfg.limits.limitBody(w, r)
+	r = r.WithContext(context.WithValue(r.Context(), configKey{}, handler.cfg))
+	r = handler.cfg.assignRequestID(rec, r)
+	r, rec.span = handler.cfg.startServerSpan(r)
+	rec.request = r
+	handler.cfg.callStartHooks(rec, r)
+	handler.cfg.cors.writeHeaders(rec, r, handler.cfg.requestIDHeader)
+	if handler.cfg.timeout > 0 {
+		handler.serveWithTimeout(rec, r)
+	} else {
+		handler.serve(rec, r)
+	}
+}
+
+// finish recovers from any panic in the handler or hooks, then logs and reports the outcome of the request to the ResponseHooks
+func (handler *wrappedHandler) finish(rec *responseRecorder, start time.Time) {
+	recovered := recover()
+	var stack []byte
+	abort := false
+	if recovered != nil {
+		// still the stack of the goroutine that panicked, since deferred calls run before it unwinds
+		stack = debug.Stack()
+		if p, ok := recovered.(*handlerPanic); ok {
+			recovered, stack = p.value, p.stack
+		}
+		abort = handler.cfg.recoverPanic(rec, recovered, stack)
+	}
+	info := ResponseInfo{
+		Request:      rec.request,
+		Status:       rec.status,
+		BytesWritten: rec.bytesWritten,
+		Duration:     time.Since(start),
+		Err:          rec.err,
+		Panic:        recovered,
+		PanicStack:   stack,
+		RequestID:    RequestID(rec.request.Context()),
+	}
+	info.Route = routeTemplate(rec.request)
+	if recovered != nil && recovered != http.ErrAbortHandler {
+		handler.cfg.logPanic(info)
+	}
+	handler.cfg.logAccess(info)
+	endServerSpan(rec.span, info)
+	for _, finish := range rec.finishers {
+		finish(info)
+	}
+	handler.cfg.callResponseHooks(info)
+	if abort {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// routeTemplate returns the path template of the mux route r matched, if any
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		template, _ := route.GetPathTemplate()
+		return template
+	}
+	return ""
+}
@@ -1,28 +1,217 @@
 package resthelper
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // PreRequestHook is for functions that run before the wrapped handler, to take care of common tasks like checking authentication tokens
 // hooks are run in the provided order; if any return an HttpError, the provided status code will be returned and neither the handler nor any subsequent hooks will run
 type PreRequestHook func(r *http.Request) *HttpError
 
-func callPreRequestHooks(hooks []PreRequestHook, r *http.Request) *HttpError {
+// ContextHook is a PreRequestHook that can also pass values on to later hooks and the handler, by returning a derived request (usually from r.WithContext)
+// returning a nil request leaves the request unchanged; see ContextKey for storing values without unchecked type assertions
+type ContextHook func(r *http.Request) (*http.Request, *HttpError)
+
+// Enrich adapts a ContextHook so it can be mixed with other PreRequestHooks; every hook after it, and the wrapped handler, receive the request it returns
+func Enrich(hook ContextHook) PreRequestHook {
+	return func(r *http.Request) *HttpError {
+		enriched, err := hook(r)
+		if err != nil {
+			return err
+		}
+		if carrier, ok := r.Context().Value(requestCarrierKey{}).(*requestCarrier); ok && enriched != nil {
+			carrier.r = enriched
+		}
+		return nil
+	}
+}
+
+type requestCarrierKey struct{}
+
+// requestCarrier lets hooks adapted by Enrich hand a derived request back to callPreRequestHooks
+type requestCarrier struct {
+	r *http.Request
+}
+
+// callPreRequestHooks runs hooks in order, returning the request as enriched by any ContextHooks
+func callPreRequestHooks(hooks []PreRequestHook, r *http.Request) (*http.Request, *HttpError) {
+	if len(hooks) == 0 {
+		return r, nil
+	}
+	carrier := &requestCarrier{}
+	r = r.WithContext(context.WithValue(r.Context(), requestCarrierKey{}, carrier))
+	carrier.r = r
+	cfg := configFromContext(r.Context())
 	for i := range hooks {
+		span := cfg.startHookSpan(r, hooks[i])
 		err := hooks[i](r)
+		endSpan(span, err)
 		if err != nil {
-			return err
+			return r, err
 		}
+		r = carrier.r
 	}
-	return nil
+	return r, nil
 }
 
 // PostResponseHook is for functions that run after the wrapped handler, to take care of common tasks like logging http status codes
+// see ResponseHook for a variant that can also see the request, route, timing and size of the response
 type PostResponseHook func(*HttpError, int)
 
-func callPostResponseHooks(hooks []PostResponseHook, httpErr *HttpError, status int) {
-	for i := range hooks {
-		go hooks[i](httpErr, status)
+// ResponseInfo describes how a request to a wrapped handler ended
+type ResponseInfo struct {
+	Request      *http.Request // including anything added by ContextHooks
+	Route        string        // the path template of the matched mux route, if any
+	Status       int
+	BytesWritten int64
+	Duration     time.Duration
+	Err          *HttpError // nil if the handler succeeded
+	Panic        any        // the recovered value if the handler or a hook panicked
+	PanicStack   []byte     // the stack of the goroutine that panicked
+	RequestID    string     // empty if request IDs are turned off
+}
+
+// RequestStartHook is called synchronously as a wrapper starts serving a request, before any PreRequestHooks, e.g. to count requests in flight
+// the function it returns, if not nil, is called synchronously with the outcome once the response is written, before any ResponseHooks run
+type RequestStartHook func(r *http.Request) func(ResponseInfo)
+
+// WithRequestStartHooks adds hooks to call as every request starts
+func WithRequestStartHooks(hooks ...RequestStartHook) Option {
+	return func(cfg *config) {
+		cfg.startHooks = append(cfg.startHooks, hooks...)
+	}
+}
+
+func (cfg *config) callStartHooks(rec *responseRecorder, r *http.Request) {
+	for _, hook := range cfg.startHooks {
+		if finish := hook(r); finish != nil {
+			rec.finishers = append(rec.finishers, finish)
+		}
+	}
+}
+
+// ResponseHook is for functions that run after the response has been written, like access logging or metrics
+type ResponseHook func(ResponseInfo)
+
+// WithResponseHooks adds hooks to run after every response; like PostResponseHooks they each run in their own goroutine unless WithSynchronousHooks or WithHookPool is used
+func WithResponseHooks(hooks ...ResponseHook) Option {
+	return func(cfg *config) {
+		cfg.responseHooks = append(cfg.responseHooks, hooks...)
+	}
+}
+
+// WithSynchronousHooks runs response hooks one after another, in order, before the wrapped handler returns to the server
+func WithSynchronousHooks() Option {
+	return func(cfg *config) {
+		cfg.runHook = func(hook func()) {
+			hook()
+		}
+	}
+}
+
+// WithHookPool runs response hooks on a shared, bounded pool of goroutines instead of starting a new one per hook
+func WithHookPool(pool *HookPool) Option {
+	return func(cfg *config) {
+		cfg.runHook = pool.submit
+	}
+}
+
+// HookPool is a fixed number of goroutines that run response hooks for any number of wrappers
+// when every worker is busy and the queue is full, responding handlers wait for room rather than dropping hooks
+type HookPool struct {
+	lock   sync.RWMutex // held for reading while submitting, so Close can't close the queue under a submitter
+	closed bool
+	queue  chan func()
+	done   sync.WaitGroup
+}
+
+// NewHookPool starts workers goroutines sharing a queue of up to queueSize pending hooks
+// it panics if workers is less than 1, since nothing would ever run the queued hooks
+func NewHookPool(workers, queueSize int) *HookPool {
+	if workers < 1 {
+		panic(fmt.Sprintf("resthelper: NewHookPool needs at least 1 worker, got %d", workers))
+	}
+	pool := &HookPool{
+		queue: make(chan func(), queueSize),
+	}
+	pool.done.Add(workers)
+	for range workers {
+		go func() {
+			defer pool.done.Done()
+			for hook := range pool.queue {
+				hook()
+			}
+		}()
+	}
+	return pool
+}
+
+func (pool *HookPool) submit(hook func()) {
+	pool.lock.RLock()
+	if pool.closed {
+		pool.lock.RUnlock()
+		// there are no workers left to run it, so the responding handler does
+		hook()
+		return
+	}
+	defer pool.lock.RUnlock()
+	pool.queue <- hook
+}
+
+// Close waits for queued hooks to finish and stops the workers; hooks submitted afterwards, by wrappers still serving requests, run synchronously instead
+// closing a pool more than once has no effect
+func (pool *HookPool) Close() {
+	pool.lock.Lock()
+	if pool.closed {
+		pool.lock.Unlock()
+		return
+	}
+	pool.closed = true
+	close(pool.queue)
+	pool.lock.Unlock()
+	pool.done.Wait()
+}
+
+func runHookAsync(hook func()) {
+	go hook()
+}
+
+// addPostResponseHooks adapts the PostResponseHooks passed to a ...WithHooks wrapper, running them ahead of any ResponseHooks
+func (cfg *config) addPostResponseHooks(hooks []PostResponseHook) {
+	adapted := make([]ResponseHook, len(hooks))
+	for i, hook := range hooks {
+		adapted[i] = func(info ResponseInfo) {
+			hook(info.Err, info.Status)
+		}
+	}
+	cfg.responseHooks = append(adapted, cfg.responseHooks...)
+}
+
+func (cfg *config) callResponseHooks(info ResponseInfo) {
+	for _, hook := range cfg.responseHooks {
+		cfg.runHook(func() {
+			hook(info)
+		})
+	}
+}
+
+// MarshalErrorHook is for reporting handlers whose results can't be encoded, such as values containing channels, NaN floats or failing MarshalJSON methods
+// these are always bugs in the handler; the client receives a 500 either way
+type MarshalErrorHook func(r *http.Request, value any, err error)
+
+// WithMarshalErrorHook calls hook synchronously whenever a handler's result fails to encode, before the 500 response is written
+func WithMarshalErrorHook(hook MarshalErrorHook) Option {
+	return func(cfg *config) {
+		cfg.marshalErrorHooks = append(cfg.marshalErrorHooks, hook)
+	}
+}
+
+func (cfg *config) reportMarshalError(r *http.Request, value any, err error) {
+	for _, hook := range cfg.marshalErrorHooks {
+		hook(r, value, err)
 	}
 }
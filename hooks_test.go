@@ -0,0 +1,176 @@
+package resthelper_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+type testUser struct {
+	Name  string
+	Admin bool
+}
+
+var testUserKey = resthelper.NewContextKey[testUser]("user")
+
+func testAuthHook(r *http.Request) (*http.Request, *resthelper.HttpError) {
+	switch r.Header.Get("Authorization") {
+	case "Bearer admin":
+		return testUserKey.WithRequestValue(r, testUser{Name: "Steve", Admin: true}), nil
+	case "Bearer user":
+		return testUserKey.WithRequestValue(r, testUser{Name: "Bob"}), nil
+	}
+	return nil, resthelper.NewHttpErrF(http.StatusUnauthorized, "unauthorized")
+}
+
+func testAdminHook(r *http.Request) *resthelper.HttpError {
+	user, ok := testUserKey.RequestValue(r)
+	if !ok || !user.Admin {
+		return resthelper.NewHttpErrF(http.StatusForbidden, "admins only")
+	}
+	return nil
+}
+
+func TestContextHooks(t *testing.T) {
+	handler := resthelper.JsonResponseWrapperWithHooks(
+		[]resthelper.PreRequestHook{resthelper.Enrich(testAuthHook), testAdminHook},
+		func(r *http.Request) (string, *resthelper.HttpError) {
+			user, _ := testUserKey.RequestValue(r)
+			return user.Name, nil
+		},
+		[]resthelper.PostResponseHook{},
+	)
+
+	cases := map[string]int{
+		"Bearer admin": http.StatusOK,
+		"Bearer user":  http.StatusForbidden,
+		"":             http.StatusUnauthorized,
+	}
+	for authorization, status := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", authorization)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != status {
+			t.Error("Authorization", authorization, "expected", status, "got", rec.Code)
+		}
+		if status == http.StatusOK && rec.Body.String() != `"Steve"` {
+			t.Error("handler did not receive the enriched request, got", rec.Body.String())
+		}
+	}
+
+	if _, ok := testUserKey.RequestValue(httptest.NewRequest("GET", "/", nil)); ok {
+		t.Error("expected no value outside of an enriched request")
+	}
+}
+
+func TestResponseHooks(t *testing.T) {
+	infos := []resthelper.ResponseInfo{}
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{name}", resthelper.JsonResponseWrapperWithHooks(
+		[]resthelper.PreRequestHook{resthelper.Enrich(testAuthHook)},
+		func(r *http.Request) (string, *resthelper.HttpError) {
+			if mux.Vars(r)["name"] == "panic" {
+				panic("oh no")
+			}
+			return "hello", nil
+		},
+		[]resthelper.PostResponseHook{},
+		resthelper.WithResponseHooks(func(info resthelper.ResponseInfo) { infos = append(infos, info) }),
+		resthelper.WithSynchronousHooks(),
+	))
+
+	for _, path := range []string{"/users/steve", "/users/panic"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer user")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/steve", nil))
+
+	if len(infos) != 3 {
+		t.Fatal("expected 3 hook calls, got", len(infos))
+	}
+	for _, info := range infos {
+		if info.Route != "/users/{name}" {
+			t.Error("unexpected route:", info.Route)
+		}
+		if info.Duration <= 0 || info.BytesWritten == 0 {
+			t.Error("expected duration and size to be recorded:", info)
+		}
+	}
+	if infos[0].Status != http.StatusOK || infos[0].Err != nil || infos[0].BytesWritten != int64(len(`"hello"`)) {
+		t.Error("unexpected info for successful request:", infos[0])
+	}
+	if user, _ := testUserKey.RequestValue(infos[0].Request); user.Name != "Bob" {
+		t.Error("expected hooks to see the enriched request")
+	}
+	if infos[1].Status != http.StatusInternalServerError || infos[1].Panic != "oh no" {
+		t.Error("unexpected info for panicking request:", infos[1])
+	}
+	if infos[2].Status != http.StatusUnauthorized || infos[2].Err == nil {
+		t.Error("unexpected info for rejected request:", infos[2])
+	}
+}
+
+func TestHookPool(t *testing.T) {
+	pool := resthelper.NewHookPool(2, 1)
+	var calls atomic.Int32
+	handler := resthelper.NoContentWrapper(testCorsHandler,
+		resthelper.WithResponseHooks(func(info resthelper.ResponseInfo) { calls.Add(1) }),
+		resthelper.WithHookPool(pool),
+	)
+	for range 10 {
+		handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	pool.Close()
+	if calls.Load() != 10 {
+		t.Error("expected every hook to run before Close returned, got", calls.Load())
+	}
+
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	pool.Close()
+	if calls.Load() != 11 {
+		t.Error("expected hooks submitted after Close to run synchronously, got", calls.Load())
+	}
+}
+
+func TestHookPoolWithoutWorkers(t *testing.T) {
+	defer func() {
+		if recovered := recover(); recovered == nil {
+			t.Error("expected a pool without workers to be rejected")
+		}
+	}()
+	resthelper.NewHookPool(0, 1)
+}
+
+func TestRequestStartHooks(t *testing.T) {
+	events := []string{}
+	handler := resthelper.NoContentWrapperWithHooks([]resthelper.PreRequestHook{func(r *http.Request) *resthelper.HttpError {
+		events = append(events, "pre-request hook")
+		return nil
+	}}, func(r *http.Request) *resthelper.HttpError {
+		events = append(events, "handler")
+		return nil
+	}, nil, resthelper.WithRequestStartHooks(func(r *http.Request) func(resthelper.ResponseInfo) {
+		events = append(events, "start")
+		return func(info resthelper.ResponseInfo) {
+			events = append(events, "finish "+http.StatusText(info.Status))
+		}
+	}))
+
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/things/1", nil))
+	expected := []string{"start", "pre-request hook", "handler", "finish No Content"}
+	if len(events) != len(expected) {
+		t.Fatal("expected", expected, "got", events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Error("expected", expected, "got", events)
+			break
+		}
+	}
+}
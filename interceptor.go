@@ -0,0 +1,83 @@
+package resthelper
+
+import (
+	"net/http"
+
+	"github.com/preston-wagner/unicycle/defaults"
+)
+
+// Interceptor runs around a handler, for tasks like opening a transaction or timing the handler alone
+// it may inspect or replace the result and error returned by next, or return without calling next at all
+type Interceptor[T any] func(r *http.Request, next JsonResponseHandler[T]) (T, *HttpError)
+
+// NoContentInterceptor is the Interceptor equivalent for NoResponseHandlers
+type NoContentInterceptor func(r *http.Request, next NoResponseHandler) *HttpError
+
+// Intercept wraps toWrap in interceptors, the first of which runs outermost
+// the result can be passed to any wrapper, where it runs after the PreRequestHooks and any WithInterceptors interceptors
+func Intercept[T any](toWrap JsonResponseHandler[T], interceptors ...Interceptor[T]) JsonResponseHandler[T] {
+	handler := toWrap
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(r *http.Request) (T, *HttpError) {
+			return interceptor(r, next)
+		}
+	}
+	return handler
+}
+
+// InterceptNoContent wraps toWrap in interceptors, the first of which runs outermost
+func InterceptNoContent(toWrap NoResponseHandler, interceptors ...NoContentInterceptor) NoResponseHandler {
+	handler := toWrap
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(r *http.Request) *HttpError {
+			return interceptor(r, next)
+		}
+	}
+	return handler
+}
+
+// WithInterceptors adds interceptors that work with any wrapper, whatever its result type; they run after the PreRequestHooks and before any interceptors applied with Intercept
+// the result they see is the handler's T (nil for no content handlers); replacing it with a value of another type is reported as a 500
+func WithInterceptors(interceptors ...Interceptor[any]) Option {
+	return func(cfg *config) {
+		cfg.interceptors = append(cfg.interceptors, interceptors...)
+	}
+}
+
+// applyInterceptors wraps toWrap in the interceptors configured with WithInterceptors
+func applyInterceptors[T any](cfg *config, toWrap JsonResponseHandler[T]) JsonResponseHandler[T] {
+	if len(cfg.interceptors) == 0 {
+		return toWrap
+	}
+	chained := Intercept(func(r *http.Request) (any, *HttpError) {
+		result, err := toWrap(r)
+		return result, err
+	}, cfg.interceptors...)
+	return func(r *http.Request) (T, *HttpError) {
+		result, err := chained(r)
+		if result == nil {
+			return defaults.ZeroValue[T](), err
+		}
+		typed, ok := result.(T)
+		if !ok {
+			return typed, NewHttpErrF(http.StatusInternalServerError, "interceptor returned %T in place of %T", result, typed)
+		}
+		return typed, err
+	}
+}
+
+// applyNoContentInterceptors wraps toWrap in the interceptors configured with WithInterceptors
+func applyNoContentInterceptors(cfg *config, toWrap NoResponseHandler) NoResponseHandler {
+	if len(cfg.interceptors) == 0 {
+		return toWrap
+	}
+	chained := applyInterceptors(cfg, func(r *http.Request) (any, *HttpError) {
+		return nil, toWrap(r)
+	})
+	return func(r *http.Request) *HttpError {
+		_, err := chained(r)
+		return err
+	}
+}
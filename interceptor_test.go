@@ -0,0 +1,102 @@
+package resthelper_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func TestInterceptors(t *testing.T) {
+	calls := []string{}
+	record := func(name string) resthelper.Interceptor[any] {
+		return func(r *http.Request, next resthelper.JsonResponseHandler[any]) (any, *resthelper.HttpError) {
+			calls = append(calls, name+" before")
+			result, err := next(r)
+			calls = append(calls, name+" after")
+			return result, err
+		}
+	}
+	doubleCount := func(r *http.Request, next resthelper.JsonResponseHandler[testJsonStruct]) (testJsonStruct, *resthelper.HttpError) {
+		calls = append(calls, "typed")
+		result, err := next(r)
+		result.Count *= 2
+		return result, err
+	}
+	shortCircuit := func(r *http.Request, next resthelper.JsonResponseHandler[any]) (any, *resthelper.HttpError) {
+		if r.URL.Query().Get("cached") != "" {
+			return testJsonStruct{Name: "cached"}, nil
+		}
+		return next(r)
+	}
+
+	handler := resthelper.JsonResponseWrapperWithHooks(
+		[]resthelper.PreRequestHook{func(r *http.Request) *resthelper.HttpError { calls = append(calls, "pre hook"); return nil }},
+		resthelper.Intercept(func(r *http.Request) (testJsonStruct, *resthelper.HttpError) {
+			calls = append(calls, "handler")
+			return testJsonStruct{Name: "Steve", Count: 7}, nil
+		}, doubleCount),
+		[]resthelper.PostResponseHook{func(*resthelper.HttpError, int) { calls = append(calls, "post hook") }},
+		resthelper.WithInterceptors(record("outer"), record("inner"), shortCircuit),
+		resthelper.WithSynchronousHooks(),
+	)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Body.String() != `{"Name":"Steve","Count":14}` {
+		t.Error("typed interceptor did not replace the result:", rec.Body.String())
+	}
+	expected := []string{"pre hook", "outer before", "inner before", "typed", "handler", "inner after", "outer after", "post hook"}
+	if len(calls) != len(expected) {
+		t.Fatal("expected calls", expected, "got", calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Error("expected calls", expected, "got", calls)
+			break
+		}
+	}
+
+	calls = []string{}
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/?cached=1", nil))
+	if rec.Body.String() != `{"Name":"cached","Count":0}` {
+		t.Error("interceptor did not short circuit:", rec.Body.String())
+	}
+	for _, call := range calls {
+		if call == "handler" || call == "typed" {
+			t.Error("handler ran despite short circuit:", calls)
+		}
+	}
+}
+
+func TestInterceptorTypeMismatch(t *testing.T) {
+	handler := resthelper.JsonResponseWrapper(func(r *http.Request) (testJsonStruct, *resthelper.HttpError) {
+		return testJsonStruct{}, nil
+	}, resthelper.WithInterceptors(func(r *http.Request, next resthelper.JsonResponseHandler[any]) (any, *resthelper.HttpError) {
+		return "not a testJsonStruct", nil
+	}))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Error("expected", http.StatusInternalServerError, "got", rec.Code)
+	}
+}
+
+func TestNoContentInterceptors(t *testing.T) {
+	forbidden := func(r *http.Request, next resthelper.NoResponseHandler) *resthelper.HttpError {
+		if err := next(r); err != nil {
+			return err
+		}
+		return resthelper.NewHttpErrF(http.StatusForbidden, "replaced")
+	}
+	handler := resthelper.NoContentWrapper(resthelper.InterceptNoContent(testCorsHandler, forbidden))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("DELETE", "/", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Error("expected", http.StatusForbidden, "got", rec.Code)
+	}
+}
@@ -1,30 +1,57 @@
 package resthelper
 
 import (
-	"encoding/json"
+	"io"
 	"net/http"
+	"reflect"
 
 	"github.com/preston-wagner/unicycle/defaults"
 )
 
 type JsonRequestHandler[REQUEST_TYPE any, RESPONSE_TYPE any] func(*http.Request, REQUEST_TYPE) (RESPONSE_TYPE, *HttpError)
 
+// DecodeRequest reads the body of r into a T using the codec matching its Content-Type (json if it has none), then fills in any fields tagged with `path:"name"`, `query:"name"` or `header:"Name"` from the mux path variables, query string and headers
+// when T has such fields, an empty body is allowed so that GET requests can be described entirely by their parameters
 func DecodeRequest[T any](r *http.Request) (T, *HttpError) {
+	_, span := StartSpan(r.Context(), "decode request")
+	req, httpErr := decodeRequest[T](r)
+	endSpan(span, httpErr)
+	return req, httpErr
+}
+
+func decodeRequest[T any](r *http.Request) (T, *HttpError) {
 	var req T
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&req)
-	if err != nil {
-		return req, NewHttpErr(http.StatusBadRequest, err)
+	params := Params(reflect.TypeFor[T]())
+	if r.Body != nil {
+		cfg := configFromContext(r.Context())
+		codec, httpErr := cfg.codecs.requestCodec(r)
+		if httpErr != nil {
+			return req, httpErr
+		}
+		err := decodeBody(r.Body, &req, codec, cfg)
+		if err != nil && !(err == io.EOF && len(params) > 0) {
+			return req, decodeError(err)
+		}
+	}
+	if len(params) > 0 {
+		if httpErr := bindParams(r, &req); httpErr != nil {
+			return req, httpErr
+		}
 	}
 	return req, nil
 }
 
+// JsonRequestWrapper decodes and validates the request body before handing it to toWrap; see DecodeRequest and ValidateRequest
 func JsonRequestWrapper[REQUEST_TYPE any, RESPONSE_TYPE any](toWrap JsonRequestHandler[REQUEST_TYPE, RESPONSE_TYPE]) func(*http.Request) (RESPONSE_TYPE, *HttpError) {
 	return func(r *http.Request) (RESPONSE_TYPE, *HttpError) {
 		body, httpErr := DecodeRequest[REQUEST_TYPE](r)
 		if httpErr != nil {
 			return defaults.ZeroValue[RESPONSE_TYPE](), httpErr
 		}
+		httpErr = ValidateRequest(body)
+		if httpErr != nil {
+			return defaults.ZeroValue[RESPONSE_TYPE](), httpErr
+		}
 		return toWrap(r, body)
 	}
 }
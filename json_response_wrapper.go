@@ -1,7 +1,7 @@
 package resthelper
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
 )
 
@@ -10,51 +10,99 @@ type DefaultMuxHandler func(w http.ResponseWriter, r *http.Request)
 type JsonResponseHandler[T any] func(*http.Request) (T, *HttpError)
 
 // JsonResponseWrapper allows us to ensure at compile time that a route handler will always return either a json response or an error code
-func JsonResponseWrapper[T any](toWrap JsonResponseHandler[T]) DefaultMuxHandler {
-	return JsonResponseWrapperWithHooks([]PreRequestHook{}, toWrap, []PostResponseHook{})
+// the response is encoded with the codec the client's Accept header prefers (see WithCodecs), which is json unless other codecs are registered
+// handlers that need a status other than 200 OK, or extra headers or cookies, can return a Response[T]
+func JsonResponseWrapper[T any](toWrap JsonResponseHandler[T], opts ...Option) DefaultMuxHandler {
+	return JsonResponseWrapperWithHooks([]PreRequestHook{}, toWrap, []PostResponseHook{}, opts...)
 }
 
 func JsonResponseWrapperWithHooks[T any](
 	preRequestHooks []PreRequestHook,
 	toWrap func(*http.Request) (T, *HttpError),
 	postResponseHooks []PostResponseHook,
+	opts ...Option,
 ) DefaultMuxHandler {
-	return func(w http.ResponseWriter, r *http.Request) {
-		defer recoverToErrorResponse(w, postResponseHooks)
-		writeCommonHeaders(w)
-		err := callPreRequestHooks(preRequestHooks, r)
+	return newJsonResponseHandler(preRequestHooks, toWrap, postResponseHooks, opts).handleFunc
+}
+
+func newJsonResponseHandler[T any](preRequestHooks []PreRequestHook, toWrap func(*http.Request) (T, *HttpError), postResponseHooks []PostResponseHook, opts []Option) *wrappedHandler {
+	cfg := newConfig(opts)
+	cfg.addPostResponseHooks(postResponseHooks)
+	toWrap = applyInterceptors(cfg, toWrap)
+	return newWrappedHandler(cfg, func(w http.ResponseWriter, r *http.Request) {
+		r, err := callPreRequestHooks(preRequestHooks, r)
+		if err != nil {
+			respondWithError(w, r, err)
+			return
+		}
+		payload, err := traceHandler(r, toWrap)
 		if err != nil {
-			respondWithError(w, err, postResponseHooks)
+			respondWithError(w, r, err)
 			return
 		}
-		payload, err := toWrap(r)
+		codec, err := cfg.codecs.responseCodec(r)
 		if err != nil {
-			respondWithError(w, err, postResponseHooks)
-		} else {
-			response, _ := json.Marshal(payload)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			w.Write(response)
-			callPostResponseHooks(postResponseHooks, nil, http.StatusOK)
+			respondWithError(w, r, err)
+			return
 		}
+		writePayload(w, r, cfg, codec, payload)
+	})
+}
+
+// writePayload writes a successful handler result encoded with codec, or a 500 through the usual error path if it can't be marshalled
+func writePayload(w http.ResponseWriter, r *http.Request, cfg *config, codec Codec, payload any) {
+	status, headers, cookies, body := unwrapPayload(payload)
+	if cfg.codecs.size() > 1 {
+		w.Header().Add("Vary", "Accept")
+	}
+	var response []byte
+	if bodyAllowed(status) {
+		var err error
+		_, span := cfg.startSpan(r.Context(), "encode response")
+		response, err = codec.Marshal(body)
+		if err != nil {
+			httpErr := NewHttpErr(http.StatusInternalServerError, fmt.Errorf("failed to encode response: %w", err))
+			endSpan(span, httpErr)
+			cfg.reportMarshalError(r, body, err)
+			// the client is only told that encoding failed, since the error can describe the handler's internals; hooks and logs get all of it
+			respondWithError(w, r, NewHttpErrF(http.StatusInternalServerError, "failed to encode response"))
+			recordOutcome(w, r, httpErr)
+			return
+		}
+		span.End()
+		w.Header().Set("Content-Type", codec.MediaType())
+	}
+	recordOutcome(w, r, nil)
+	for key, values := range headers {
+		w.Header()[http.CanonicalHeaderKey(key)] = values
+	}
+	for _, cookie := range cookies {
+		http.SetCookie(w, cookie)
+	}
+	w.WriteHeader(status)
+	if response != nil {
+		w.Write(response)
 	}
 }
 
 // JsonToJsonWrapper simplifies the common case where both the body of the request and the response should be json
 func JsonToJsonWrapper[REQUEST_TYPE any, RESPONSE_TYPE any](
 	toWrap JsonRequestHandler[REQUEST_TYPE, RESPONSE_TYPE],
+	opts ...Option,
 ) DefaultMuxHandler {
-	return JsonResponseWrapper(JsonRequestWrapper(toWrap))
+	return JsonResponseWrapper(JsonRequestWrapper(toWrap), opts...)
 }
 
 func JsonToJsonWrapperWithHooks[REQUEST_TYPE any, RESPONSE_TYPE any](
 	preRequestHooks []PreRequestHook,
 	toWrap JsonRequestHandler[REQUEST_TYPE, RESPONSE_TYPE],
 	postResponseHooks []PostResponseHook,
+	opts ...Option,
 ) DefaultMuxHandler {
 	return JsonResponseWrapperWithHooks(
 		preRequestHooks,
 		JsonRequestWrapper(toWrap),
 		postResponseHooks,
+		opts...,
 	)
 }
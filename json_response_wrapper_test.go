@@ -2,6 +2,7 @@ package resthelper_test
 
 import (
 	"errors"
+	"net"
 	"net/http"
 	"strconv"
 	"testing"
@@ -61,8 +62,12 @@ func TestJsonToJsonWrapper(t *testing.T) {
 		Handler:      router,
 	}
 	defer server.Close()
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
 	serverRunPromise := promises.WrapInPromise(func() (bool, error) {
-		err := server.ListenAndServe()
+		err := server.Serve(listener)
 		return err == http.ErrServerClosed, err
 	})
 
@@ -114,6 +119,7 @@ func TestJsonToJsonWrapperWithHooks(t *testing.T) {
 		[]resthelper.PreRequestHook{set_pre_hook_called},
 		testJsonHandler,
 		[]resthelper.PostResponseHook{set_post_hook_called},
+		resthelper.WithSynchronousHooks(),
 	)).Methods("POST")
 
 	post_hook_status := 0
@@ -125,6 +131,7 @@ func TestJsonToJsonWrapperWithHooks(t *testing.T) {
 		[]resthelper.PreRequestHook{testErrorPreRequestHandler},
 		testErrorHandler, // prerequest hook throws the error, this should not be called
 		[]resthelper.PostResponseHook{set_post_hook_status},
+		resthelper.WithSynchronousHooks(),
 	)).Methods("POST")
 
 	const port = 9876
@@ -136,8 +143,12 @@ func TestJsonToJsonWrapperWithHooks(t *testing.T) {
 		Handler:      router,
 	}
 	defer server.Close()
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
 	serverRunPromise := promises.WrapInPromise(func() (bool, error) {
-		err := server.ListenAndServe()
+		err := server.Serve(listener)
 		return err == http.ErrServerClosed, err
 	})
 
@@ -0,0 +1,32 @@
+package resthelper
+
+import (
+	"net/http"
+)
+
+// Limits bound how much work a single request body can cause; zero values mean no limit
+type Limits struct {
+	// MaxBodyBytes caps the size of the request body, enforced with http.MaxBytesReader; larger bodies are rejected with a 413
+	MaxBodyBytes int64
+	// MaxDepth caps how deeply json arrays and objects may nest
+	MaxDepth int
+	// MaxElements caps the number of elements in any single json array, or keys in any single json object
+	MaxElements int
+}
+
+// WithLimits rejects request bodies that exceed limits before they are decoded and before the handler runs
+func WithLimits(limits Limits) Option {
+	return func(cfg *config) {
+		cfg.limits = limits
+	}
+}
+
+func (limits Limits) checksStructure() bool {
+	return limits.MaxDepth > 0 || limits.MaxElements > 0
+}
+
+func (limits Limits) limitBody(w http.ResponseWriter, r *http.Request) {
+	if limits.MaxBodyBytes > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBodyBytes)
+	}
+}
@@ -0,0 +1,41 @@
+package resthelper_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+type testNested struct {
+	Values []any
+}
+
+func TestLimits(t *testing.T) {
+	handler := resthelper.JsonToJsonWrapper(func(r *http.Request, input testNested) (testNested, *resthelper.HttpError) {
+		return input, nil
+	}, resthelper.WithLimits(resthelper.Limits{
+		MaxBodyBytes: 64,
+		MaxDepth:     3,
+		MaxElements:  4,
+	}))
+
+	cases := []struct {
+		body   string
+		status int
+	}{
+		{`{"Values":[1,2,3,4]}`, http.StatusOK},
+		{`{"Values":[1,{"a":2}]} trailing`, http.StatusOK},
+		{`{"Values":[1,2,3,4,5]}`, http.StatusBadRequest},
+		{`{"Values":[{"a":1,"b":2,"c":3,"d":4,"e":5}]}`, http.StatusBadRequest},
+		{`{"Values":[[[1]]]}`, http.StatusBadRequest},
+		{`{"Values":["` + strings.Repeat("x", 64) + `"]}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, c := range cases {
+		status, problem := decodeProblem(t, handler, c.body)
+		if status != c.status {
+			t.Error("expected", c.status, "for", c.body, "got", status, problem["detail"])
+		}
+	}
+}
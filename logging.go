@@ -0,0 +1,89 @@
+package resthelper
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// LogAttrsFunc returns attributes to add to every log line written about a request, e.g. the authenticated user from its context
+type LogAttrsFunc func(r *http.Request) []slog.Attr
+
+// WithLogger sets the logger wrappers report panics, handlers finishing after their timeout and, with WithAccessLog, requests to; slog.Default() is used otherwise
+// attributes added with logger.With appear on every line
+func WithLogger(logger *slog.Logger) Option {
+	return func(cfg *config) {
+		cfg.logger = logger
+	}
+}
+
+// WithAccessLog logs a line for every request once its response is written, with its method, route template, status, duration, bytes written and error
+// responses with 5xx statuses are logged at error level, everything else at info level
+func WithAccessLog(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.accessLog = enabled
+	}
+}
+
+// WithLogAttrs adds attributes from each request to the lines logged about it
+func WithLogAttrs(funcs ...LogAttrsFunc) Option {
+	return func(cfg *config) {
+		cfg.logAttrs = append(cfg.logAttrs, funcs...)
+	}
+}
+
+func (cfg *config) log() *slog.Logger {
+	if cfg.logger != nil {
+		return cfg.logger
+	}
+	return slog.Default()
+}
+
+// requestAttrs describes the request behind info, including the attributes of any LogAttrsFuncs
+func (cfg *config) requestAttrs(info ResponseInfo) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("method", info.Request.Method),
+		slog.String("route", info.Route),
+		slog.String("path", info.Request.URL.Path),
+	}
+	if info.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", info.RequestID))
+	}
+	for _, logAttrs := range cfg.logAttrs {
+		attrs = append(attrs, logAttrs(info.Request)...)
+	}
+	return attrs
+}
+
+// logPanic reports a panic recovered while serving a request, with the stack of the goroutine that panicked
+func (cfg *config) logPanic(info ResponseInfo) {
+	attrs := append(cfg.requestAttrs(info),
+		slog.Any("panic", info.Panic),
+		slog.String("stack", string(info.PanicStack)),
+	)
+	cfg.log().LogAttrs(info.Request.Context(), slog.LevelError, "panic serving request", attrs...)
+}
+
+// logLateHandler reports a handler that returned after its timeout, once its dropped response has been written; handlers that keep doing so probably ignore their request's context
+func (cfg *config) logLateHandler(info ResponseInfo) {
+	attrs := append(cfg.requestAttrs(info), slog.Duration("duration", info.Duration))
+	cfg.log().LogAttrs(info.Request.Context(), slog.LevelWarn, "handler finished after its timeout", attrs...)
+}
+
+func (cfg *config) logAccess(info ResponseInfo) {
+	if !cfg.accessLog {
+		return
+	}
+	attrs := append(cfg.requestAttrs(info),
+		slog.Int("status", info.Status),
+		slog.Duration("duration", info.Duration),
+		slog.Int64("bytes", info.BytesWritten),
+	)
+	if info.Err != nil {
+		attrs = append(attrs, slog.String("error", info.Err.Error()))
+	}
+	level := slog.LevelInfo
+	if info.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	cfg.log().LogAttrs(info.Request.Context(), level, "request", attrs...)
+}
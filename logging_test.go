@@ -0,0 +1,90 @@
+package resthelper_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func decodeLogLines(t *testing.T, buffer *bytes.Buffer) []map[string]any {
+	lines := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		decoded := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, decoded)
+	}
+	return lines
+}
+
+func TestPanicLogging(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buffer, nil)).With("service", "things")
+	router := mux.NewRouter()
+	router.HandleFunc("/things/{id}", resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		panic("oh no")
+	}, resthelper.WithLogger(logger)))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/things/1", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Error("expected", http.StatusInternalServerError, "got", rec.Code)
+	}
+
+	lines := decodeLogLines(t, buffer)
+	if len(lines) != 1 {
+		t.Fatal("expected a single panic report, got", lines)
+	}
+	report := lines[0]
+	if report["level"] != "ERROR" || report["msg"] != "panic serving request" || report["panic"] != "oh no" {
+		t.Error("unexpected panic report:", report)
+	}
+	if report["route"] != "/things/{id}" || report["method"] != http.MethodDelete || report["service"] != "things" {
+		t.Error("expected request metadata and the logger's own attributes:", report)
+	}
+	if stack, _ := report["stack"].(string); !strings.Contains(stack, "logging_test.go") {
+		t.Error("expected the stack to include the panicking frame:", stack)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	router := mux.NewRouter()
+	router.HandleFunc("/things/{id}", resthelper.JsonResponseWrapper(testProblemHandler,
+		resthelper.WithLogger(slog.New(slog.NewJSONHandler(buffer, nil))),
+		resthelper.WithAccessLog(true),
+		resthelper.WithLogAttrs(func(r *http.Request) []slog.Attr {
+			return []slog.Attr{slog.String("tenant", r.Header.Get("X-Tenant"))}
+		}),
+	))
+
+	req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+	req.Header.Set("X-Tenant", "acme")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := decodeLogLines(t, buffer)
+	if len(lines) != 1 {
+		t.Fatal("expected a single access log line, got", lines)
+	}
+	line := lines[0]
+	if line["level"] != "INFO" || line["msg"] != "request" || line["route"] != "/things/{id}" || line["status"] != float64(http.StatusConflict) {
+		t.Error("unexpected access log line:", line)
+	}
+	if line["error"] != "Steve already exists" || line["bytes"].(float64) == 0 || line["tenant"] != "acme" {
+		t.Error("expected the error, size and custom attributes:", line)
+	}
+	if _, ok := line["duration"]; !ok {
+		t.Error("expected the duration:", line)
+	}
+}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler serves the collected metrics for Prometheus to scrape
+func (metrics *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		metrics.WriteTo(w)
+	})
+}
+
+// WriteTo writes the collected metrics in the Prometheus text exposition format, with series in a stable order
+func (metrics *Metrics) WriteTo(w io.Writer) (int64, error) {
+	requests, inFlight := metrics.snapshot()
+	out := &countingWriter{writer: bufio.NewWriter(w)}
+	name := metrics.namespace + "_requests_total"
+	out.header(name, "counter", "Requests served, by route, method and status class.")
+	for _, series := range requests {
+		out.sample(name, series.labels.labels(), float64(series.count))
+	}
+	name = metrics.namespace + "_request_duration_seconds"
+	out.header(name, "histogram", "Time taken to serve requests, by route, method and status class.")
+	for _, series := range requests {
+		out.histogram(name, series.labels.labels(), series.duration)
+	}
+	name = metrics.namespace + "_response_size_bytes"
+	out.header(name, "histogram", "Size of response bodies, by route, method and status class.")
+	for _, series := range requests {
+		out.histogram(name, series.labels.labels(), series.size)
+	}
+	name = metrics.namespace + "_requests_in_flight"
+	out.header(name, "gauge", "Requests currently being served, by route and method.")
+	for _, gauge := range inFlight {
+		out.sample(name, gauge.labels.labels(), float64(gauge.value))
+	}
+
+	if out.err == nil {
+		out.err = out.writer.Flush()
+	}
+	return out.written, out.err
+}
+
+type requestSnapshot struct {
+	labels   requestLabels
+	count    uint64
+	duration *histogram
+	size     *histogram
+}
+
+type inFlightSnapshot struct {
+	labels routeLabels
+	value  int64
+}
+
+// snapshot copies the series under the lock, sorted, so that WriteTo doesn't hold it while writing to a slow scraper
+// the in-flight gauge is reported for every route and method that has been served, at zero when nothing is in flight, as well as for any still being served for the first time
+func (metrics *Metrics) snapshot() ([]requestSnapshot, []inFlightSnapshot) {
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	requests := make([]requestSnapshot, 0, len(metrics.requests))
+	inFlight := make([]inFlightSnapshot, 0, len(metrics.inFlight))
+	seen := map[routeLabels]bool{}
+	for key, series := range metrics.requests {
+		requests = append(requests, requestSnapshot{
+			labels:   key,
+			count:    series.count,
+			duration: series.duration.clone(),
+			size:     series.size.clone(),
+		})
+		if !seen[key.routeLabels] {
+			seen[key.routeLabels] = true
+			inFlight = append(inFlight, inFlightSnapshot{labels: key.routeLabels, value: metrics.inFlight[key.routeLabels]})
+		}
+	}
+	for key, value := range metrics.inFlight {
+		if !seen[key] {
+			inFlight = append(inFlight, inFlightSnapshot{labels: key, value: value})
+		}
+	}
+
+	slices.SortFunc(requests, func(a, b requestSnapshot) int {
+		return compareRequestLabels(a.labels, b.labels)
+	})
+	slices.SortFunc(inFlight, func(a, b inFlightSnapshot) int {
+		return compareRouteLabels(a.labels, b.labels)
+	})
+	return requests, inFlight
+}
+
+func compareRouteLabels(a, b routeLabels) int {
+	return cmp.Or(strings.Compare(a.route, b.route), strings.Compare(a.method, b.method))
+}
+
+func compareRequestLabels(a, b requestLabels) int {
+	return cmp.Or(compareRouteLabels(a.routeLabels, b.routeLabels), strings.Compare(a.statusClass, b.statusClass))
+}
+
+func (labels routeLabels) labels() []string {
+	return []string{"route", labels.route, "method", labels.method}
+}
+
+func (labels requestLabels) labels() []string {
+	return append(labels.routeLabels.labels(), "status_class", labels.statusClass)
+}
+
+// countingWriter writes exposition lines, remembering the first error so callers can check once at the end
+type countingWriter struct {
+	writer  *bufio.Writer
+	written int64
+	err     error
+}
+
+func (out *countingWriter) line(format string, args ...any) {
+	if out.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(out.writer, format+"\n", args...)
+	out.written += int64(n)
+	out.err = err
+}
+
+func (out *countingWriter) header(name, metricType, help string) {
+	out.line("# HELP %s %s", name, help)
+	out.line("# TYPE %s %s", name, metricType)
+}
+
+func (out *countingWriter) sample(name string, labels []string, value float64) {
+	out.line("%s%s %s", name, formatLabels(labels), formatValue(value))
+}
+
+func (out *countingWriter) histogram(name string, labels []string, h *histogram) {
+	cumulative := uint64(0)
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		out.sample(name+"_bucket", append(slices.Clone(labels), "le", formatValue(bound)), float64(cumulative))
+	}
+	out.sample(name+"_bucket", append(slices.Clone(labels), "le", "+Inf"), float64(h.count))
+	out.sample(name+"_sum", labels, h.sum)
+	out.sample(name+"_count", labels, float64(h.count))
+}
+
+// formatLabels writes alternating label names and values as {name="value",...}
+func formatLabels(labels []string) string {
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelEscaper.Replace(labels[i+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,157 @@
+// Package metrics instruments resthelper wrappers with request counts, latency and response size histograms and in-flight gauges, exposed in the Prometheus text format
+package metrics
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the latency histogram buckets
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets are the upper bounds, in bytes, of the response size histogram buckets
+var DefaultSizeBuckets = []float64{100, 1000, 10_000, 100_000, 1_000_000, 10_000_000}
+
+// Config customizes the metrics a Metrics collects
+type Config struct {
+	Namespace       string    // prefixes every metric name; "resthelper" if empty
+	DurationBuckets []float64 // DefaultDurationBuckets if empty
+	SizeBuckets     []float64 // DefaultSizeBuckets if empty
+}
+
+// Metrics collects measurements of every request served by the wrappers it is passed to with Option
+// requests are labelled by route template, method and status class (2xx, 4xx and so on); requests that matched no mux route have an empty route label
+type Metrics struct {
+	namespace       string
+	durationBuckets []float64
+	sizeBuckets     []float64
+
+	lock     sync.Mutex
+	requests map[requestLabels]*requestSeries
+	inFlight map[routeLabels]int64
+}
+
+type routeLabels struct {
+	route  string
+	method string
+}
+
+type requestLabels struct {
+	routeLabels
+	statusClass string
+}
+
+type requestSeries struct {
+	count    uint64
+	duration *histogram
+	size     *histogram
+}
+
+// New creates a Metrics with nothing collected yet, filling in defaults for any Config fields left empty
+func New(config Config) *Metrics {
+	metrics := &Metrics{
+		namespace:       config.Namespace,
+		durationBuckets: config.DurationBuckets,
+		sizeBuckets:     config.SizeBuckets,
+		requests:        map[requestLabels]*requestSeries{},
+		inFlight:        map[routeLabels]int64{},
+	}
+	if metrics.namespace == "" {
+		metrics.namespace = "resthelper"
+	}
+	if len(metrics.durationBuckets) == 0 {
+		metrics.durationBuckets = DefaultDurationBuckets
+	}
+	if len(metrics.sizeBuckets) == 0 {
+		metrics.sizeBuckets = DefaultSizeBuckets
+	}
+	metrics.durationBuckets = sortedBuckets(metrics.durationBuckets)
+	metrics.sizeBuckets = sortedBuckets(metrics.sizeBuckets)
+	return metrics
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	sorted := slices.Clone(buckets)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// Option instruments a wrapper; pass it to SetDefaultOptions to instrument every route, or to a Group's options
+func (metrics *Metrics) Option() resthelper.Option {
+	return resthelper.WithRequestStartHooks(metrics.start)
+}
+
+func (metrics *Metrics) start(r *http.Request) func(resthelper.ResponseInfo) {
+	labels := routeLabels{method: r.Method}
+	if route := mux.CurrentRoute(r); route != nil {
+		labels.route, _ = route.GetPathTemplate()
+	}
+	metrics.lock.Lock()
+	metrics.inFlight[labels]++
+	metrics.lock.Unlock()
+	return func(info resthelper.ResponseInfo) {
+		metrics.finish(labels, info)
+	}
+}
+
+func (metrics *Metrics) finish(labels routeLabels, info resthelper.ResponseInfo) {
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+	if metrics.inFlight[labels]--; metrics.inFlight[labels] == 0 {
+		// routes that have been served are still reported, at zero, from their request series
+		delete(metrics.inFlight, labels)
+	}
+	key := requestLabels{routeLabels: labels, statusClass: statusClass(info.Status)}
+	series, ok := metrics.requests[key]
+	if !ok {
+		series = &requestSeries{
+			duration: newHistogram(metrics.durationBuckets),
+			size:     newHistogram(metrics.sizeBuckets),
+		}
+		metrics.requests[key] = series
+	}
+	series.count++
+	series.duration.observe(info.Duration.Seconds())
+	series.size.observe(float64(info.BytesWritten))
+}
+
+// statusClass groups statuses by their first digit, keeping the number of label values small
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+type histogram struct {
+	bounds []float64
+	counts []uint64 // counts[i] is the number of observations above bounds[i-1] and no greater than bounds[i]; cumulative counts are worked out when exposed
+	count  uint64
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+func (h *histogram) clone() *histogram {
+	cloned := *h
+	cloned.counts = slices.Clone(h.counts)
+	return &cloned
+}
+
+func (h *histogram) observe(value float64) {
+	h.count++
+	h.sum += value
+	if i, _ := slices.BinarySearch(h.bounds, value); i < len(h.bounds) {
+		h.counts[i]++
+	}
+}
@@ -0,0 +1,64 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+	"github.com/preston-wagner/go-resthelper/metrics"
+)
+
+type testThing struct {
+	Name string `json:"name"`
+}
+
+func TestMetrics(t *testing.T) {
+	collected := metrics.New(metrics.Config{Namespace: "api", DurationBuckets: []float64{60, 0.5}})
+	var inFlight string
+	router := mux.NewRouter()
+	router.HandleFunc("/things/{id}", resthelper.JsonResponseWrapper(func(r *http.Request) (testThing, *resthelper.HttpError) {
+		if mux.Vars(r)["id"] == "missing" {
+			return testThing{}, resthelper.NewHttpErrF(http.StatusNotFound, "no such thing")
+		}
+		rec := httptest.NewRecorder()
+		collected.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		inFlight = rec.Body.String()
+		return testThing{Name: "widget"}, nil
+	}, collected.Option())).Methods(http.MethodGet)
+
+	for _, id := range []string{"1", "2", "missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/things/"+id, nil))
+	}
+
+	if !strings.Contains(inFlight, `api_requests_in_flight{route="/things/{id}",method="GET"} 1`) {
+		t.Error("expected the request being served to be in flight:\n", inFlight)
+	}
+
+	rec := httptest.NewRecorder()
+	collected.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if contentType := rec.Header().Get("Content-Type"); contentType != metrics.ContentType {
+		t.Error("unexpected content type:", contentType)
+	}
+	exposed := rec.Body.String()
+	expected := []string{
+		"# TYPE api_requests_total counter",
+		`api_requests_total{route="/things/{id}",method="GET",status_class="2xx"} 2`,
+		`api_requests_total{route="/things/{id}",method="GET",status_class="4xx"} 1`,
+		"# TYPE api_request_duration_seconds histogram",
+		`api_request_duration_seconds_bucket{route="/things/{id}",method="GET",status_class="2xx",le="0.5"} 2`,
+		`api_request_duration_seconds_bucket{route="/things/{id}",method="GET",status_class="2xx",le="60"} 2`,
+		`api_request_duration_seconds_bucket{route="/things/{id}",method="GET",status_class="2xx",le="+Inf"} 2`,
+		`api_request_duration_seconds_count{route="/things/{id}",method="GET",status_class="4xx"} 1`,
+		`api_response_size_bytes_bucket{route="/things/{id}",method="GET",status_class="2xx",le="100"} 2`,
+		`api_response_size_bytes_sum{route="/things/{id}",method="GET",status_class="2xx"} 34`,
+		`api_requests_in_flight{route="/things/{id}",method="GET"} 0`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(exposed, line+"\n") {
+			t.Error("expected exposition to contain", line, "\ngot\n", exposed)
+		}
+	}
+}
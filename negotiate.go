@@ -0,0 +1,78 @@
+package resthelper
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept splits an Accept header into its media ranges, skipping any that are malformed
+func parseAccept(header string) []mediaRange {
+	ranges := []mediaRange{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if rawQ, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(rawQ, 64)
+			if err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// explicitQuality returns the quality the Accept ranges assign to mediaType when it is named exactly, ignoring wildcards
+func explicitQuality(ranges []mediaRange, mediaType string) float64 {
+	for _, accepted := range ranges {
+		if accepted.mediaType == mediaType {
+			return accepted.q
+		}
+	}
+	return 0
+}
+
+// acceptsProblemJson reports whether the client explicitly asked for json error bodies over plain text
+// wildcard ranges like */* are not enough, so clients that never heard of RFC 7807 keep getting plain text
+func acceptsProblemJson(r *http.Request) bool {
+	ranges := parseAccept(r.Header.Get("Accept"))
+	problemQ := max(explicitQuality(ranges, problemJsonContentType), explicitQuality(ranges, "application/json"))
+	return problemQ > 0 && problemQ >= explicitQuality(ranges, "text/plain")
+}
+
+// quality returns the quality the Accept ranges assign to mediaType, using the most specific matching range as RFC 9110 requires
+// a range with a structured syntax suffix, like application/problem+json, also matches its base type
+func quality(ranges []mediaRange, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	bestSpecificity, bestQ := -1, 0.0
+	for _, accepted := range ranges {
+		specificity := -1
+		_, suffix, hasSuffix := strings.Cut(accepted.mediaType, "+")
+		switch {
+		case accepted.mediaType == mediaType:
+			specificity = 2
+		case accepted.mediaType == mainType+"/*", hasSuffix && mainType+"/"+suffix == mediaType:
+			specificity = 1
+		case accepted.mediaType == "*/*":
+			specificity = 0
+		}
+		if specificity > bestSpecificity {
+			bestSpecificity, bestQ = specificity, accepted.q
+		}
+	}
+	return bestQ
+}
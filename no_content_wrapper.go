@@ -7,25 +7,32 @@ import (
 type NoResponseHandler func(*http.Request) *HttpError
 
 // NoContentWrapper allows us to ensure at compile time that a route handler will always return either a 204 No Content response or an error code
-func NoContentWrapper(toWrap NoResponseHandler) func(http.ResponseWriter, *http.Request) {
-	return NoContentWrapperWithHooks([]PreRequestHook{}, toWrap, []PostResponseHook{})
+func NoContentWrapper(toWrap NoResponseHandler, opts ...Option) func(http.ResponseWriter, *http.Request) {
+	return NoContentWrapperWithHooks([]PreRequestHook{}, toWrap, []PostResponseHook{}, opts...)
 }
 
-func NoContentWrapperWithHooks(preRequestHooks []PreRequestHook, toWrap NoResponseHandler, postResponseHooks []PostResponseHook) func(http.ResponseWriter, *http.Request) {
-	return func(w http.ResponseWriter, r *http.Request) {
-		defer recoverToErrorResponse(w, postResponseHooks)
-		writeCommonHeaders(w)
-		err := callPreRequestHooks(preRequestHooks, r)
+func NoContentWrapperWithHooks(preRequestHooks []PreRequestHook, toWrap NoResponseHandler, postResponseHooks []PostResponseHook, opts ...Option) func(http.ResponseWriter, *http.Request) {
+	return newNoContentHandler(preRequestHooks, toWrap, postResponseHooks, opts).handleFunc
+}
+
+func newNoContentHandler(preRequestHooks []PreRequestHook, toWrap NoResponseHandler, postResponseHooks []PostResponseHook, opts []Option) *wrappedHandler {
+	cfg := newConfig(opts)
+	cfg.addPostResponseHooks(postResponseHooks)
+	toWrap = applyNoContentInterceptors(cfg, toWrap)
+	return newWrappedHandler(cfg, func(w http.ResponseWriter, r *http.Request) {
+		r, err := callPreRequestHooks(preRequestHooks, r)
 		if err != nil {
-			respondWithError(w, err, postResponseHooks)
+			respondWithError(w, r, err)
 			return
 		}
-		err = toWrap(r)
+		_, err = traceHandler(r, func(r *http.Request) (struct{}, *HttpError) {
+			return struct{}{}, toWrap(r)
+		})
 		if err != nil {
-			respondWithError(w, err, postResponseHooks)
+			respondWithError(w, r, err)
 		} else {
+			recordOutcome(w, r, nil)
 			w.WriteHeader(http.StatusNoContent)
-			callPostResponseHooks(postResponseHooks, nil, http.StatusNoContent)
 		}
-	}
+	})
 }
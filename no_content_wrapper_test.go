@@ -1,6 +1,7 @@
 package resthelper
 
 import (
+	"net"
 	"net/http"
 	"strconv"
 	"testing"
@@ -31,7 +32,7 @@ func TestNoContentWrapper(t *testing.T) {
 	router.HandleFunc(noResponseRoute, NoContentWrapper(testNoResponseHandler)).Methods("GET")
 
 	unauthorizedRoute := "/unauthorized/"
-	router.HandleFunc(unauthorizedRoute, NoContentWrapperWithHooks([]PreRequestHook{unauthorizedHook}, testNoResponseHandler, []PostResponseHook{postHook})).Methods("GET")
+	router.HandleFunc(unauthorizedRoute, NoContentWrapperWithHooks([]PreRequestHook{unauthorizedHook}, testNoResponseHandler, []PostResponseHook{postHook}, WithSynchronousHooks())).Methods("GET")
 
 	const port = 9876
 	server := &http.Server{
@@ -42,8 +43,12 @@ func TestNoContentWrapper(t *testing.T) {
 		Handler:      router,
 	}
 	defer server.Close()
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
 	serverRunPromise := promises.WrapInPromise(func() (bool, error) {
-		err := server.ListenAndServe()
+		err := server.Serve(listener)
 		return err == http.ErrServerClosed, err
 	})
 
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+//go:embed explorer
+var explorerAssets embed.FS
+
+var explorerPage = template.Must(template.ParseFS(explorerAssets, "explorer/index.html"))
+
+// Explorer configures the API explorer page, which lists every operation in the generated document and can send requests to them from the browser
+// it is disabled unless Enabled is set, since it invites anyone who can reach it to try out the API
+type Explorer struct {
+	Enabled bool
+	// SpecURL is where the page fetches the document from, usually the path given to Mount
+	SpecURL string
+}
+
+// MountExplorer serves the explorer page from GET requests to path, and its embedded scripts and styles from beneath it, or does nothing and returns nil if the explorer isn't enabled
+func MountExplorer(router *mux.Router, path string, explorer Explorer) *mux.Route {
+	if !explorer.Enabled {
+		return nil
+	}
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return router.PathPrefix(path).Methods(http.MethodGet).Handler(explorer.handler(path))
+}
+
+func (explorer Explorer) handler(base string) http.Handler {
+	assets, err := fs.Sub(explorerAssets, "explorer")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix(base, http.FileServer(http.FS(assets)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// everything the page needs is served from here, so it can be locked down to its own origin
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		if r.URL.Path != base {
+			files.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		explorerPage.Execute(w, map[string]string{
+			"Base":    base,
+			"SpecURL": explorer.SpecURL,
+		})
+	})
+}
@@ -0,0 +1,185 @@
+"use strict";
+
+// the explorer renders every operation in the OpenAPI document resthelper generates, and sends requests built from a form
+
+const specUrl = document.body.dataset.specUrl;
+
+function element(tag, attributes, ...children) {
+	const node = document.createElement(tag);
+	for (const [name, value] of Object.entries(attributes || {})) {
+		node.setAttribute(name, value);
+	}
+	for (const child of children) {
+		node.append(child);
+	}
+	return node;
+}
+
+// resolve follows a local $ref to the component it names
+function resolve(spec, schema) {
+	while (schema && schema.$ref) {
+		schema = spec.components.schemas[schema.$ref.replace("#/components/schemas/", "")];
+	}
+	return schema || {};
+}
+
+// sample builds an example value from a schema, for prefilling request bodies
+function sample(spec, schema, seen) {
+	seen = seen || new Set();
+	if (schema && schema.$ref) {
+		if (seen.has(schema.$ref)) {
+			return null;
+		}
+		seen = new Set(seen).add(schema.$ref);
+	}
+	schema = resolve(spec, schema);
+	if (schema.anyOf) {
+		return sample(spec, schema.anyOf[0], seen);
+	}
+	if (schema.enum) {
+		return schema.enum[0];
+	}
+	const type = Array.isArray(schema.type) ? schema.type[0] : schema.type;
+	switch (type) {
+		case "object": {
+			const value = {};
+			for (const [name, property] of Object.entries(schema.properties || {})) {
+				value[name] = sample(spec, property, seen);
+			}
+			return value;
+		}
+		case "array":
+			return [sample(spec, schema.items, seen)];
+		case "string":
+			return schema.format === "date-time" ? new Date().toISOString() : "";
+		case "integer":
+		case "number":
+			return schema.minimum || 0;
+		case "boolean":
+			return false;
+		default:
+			return null;
+	}
+}
+
+function schemaBlock(spec, title, content) {
+	const block = element("div");
+	for (const [mediaType, media] of Object.entries(content || {})) {
+		block.append(element("h4", {}, `${title} (${mediaType})`));
+		const schema = media.schema && media.schema.$ref ? { [media.schema.$ref]: resolve(spec, media.schema) } : media.schema;
+		block.append(element("pre", {}, JSON.stringify(schema, null, 2)));
+	}
+	return block;
+}
+
+function renderOperation(spec, path, method, operation) {
+	const body = element("div", { class: "operation-body" });
+	if (operation.description) {
+		body.append(element("p", {}, operation.description));
+	}
+
+	const inputs = [];
+	for (const parameter of operation.parameters || []) {
+		const input = element("input", { type: "text", placeholder: parameter.schema && parameter.schema.type ? String(parameter.schema.type) : "" });
+		inputs.push({ parameter, input });
+		body.append(element("label", {}, element("span", {}, `${parameter.in} ${parameter.name}${parameter.required ? " *" : ""}`), input));
+	}
+
+	let bodyInput = null;
+	if (operation.requestBody) {
+		const content = operation.requestBody.content || {};
+		const media = Object.values(content)[0] || {};
+		bodyInput = element("textarea", { spellcheck: "false" });
+		bodyInput.value = JSON.stringify(sample(spec, media.schema), null, 2);
+		body.append(schemaBlock(spec, "Request body", content));
+		body.append(element("label", {}, element("span", {}, "body")), bodyInput);
+	}
+
+	for (const [status, response] of Object.entries(operation.responses || {})) {
+		body.append(element("h4", {}, `${status} ${response.description}`));
+		if (response.content) {
+			body.append(schemaBlock(spec, "Response body", response.content));
+		}
+	}
+
+	const result = element("pre", { class: "result" });
+	const send = element("button", { type: "button" }, "Send request");
+	send.addEventListener("click", async () => {
+		let url = path;
+		const query = new URLSearchParams();
+		const headers = new Headers({ Accept: "application/json, application/problem+json" });
+		for (const { parameter, input } of inputs) {
+			if (input.value === "") {
+				continue;
+			}
+			switch (parameter.in) {
+				case "path":
+					url = url.replace(`{${parameter.name}}`, encodeURIComponent(input.value));
+					break;
+				case "query":
+					query.append(parameter.name, input.value);
+					break;
+				case "header":
+					headers.set(parameter.name, input.value);
+					break;
+			}
+		}
+		if ([...query].length > 0) {
+			url += "?" + query;
+		}
+		const init = { method: method.toUpperCase(), headers };
+		if (bodyInput && bodyInput.value.trim() !== "") {
+			headers.set("Content-Type", "application/json");
+			init.body = bodyInput.value;
+		}
+		result.className = "result";
+		result.textContent = "…";
+		try {
+			const response = await fetch(url, init);
+			const text = await response.text();
+			let formatted = text;
+			try {
+				formatted = JSON.stringify(JSON.parse(text), null, 2);
+			} catch (e) {
+				// not json; show it as it is
+			}
+			const responseHeaders = [...response.headers].map(([name, value]) => `${name}: ${value}`).join("\n");
+			result.className = "result " + (response.ok ? "success" : "error");
+			result.textContent = `${response.status} ${response.statusText}\n${responseHeaders}\n\n${formatted}`;
+		} catch (e) {
+			result.className = "result error";
+			result.textContent = String(e);
+		}
+	});
+	body.append(send, result);
+
+	const summary = element("summary", {}, element("span", { class: `method ${method}` }, method), path);
+	if (operation.summary) {
+		summary.append(` — ${operation.summary}`);
+	}
+	return element("details", { class: "operation" }, summary, body);
+}
+
+async function main() {
+	const container = document.getElementById("operations");
+	try {
+		const response = await fetch(specUrl, { headers: { Accept: "application/json" } });
+		if (!response.ok) {
+			throw new Error(`${specUrl} responded with ${response.status}`);
+		}
+		const spec = await response.json();
+		document.title = spec.info.title;
+		document.getElementById("title").textContent = `${spec.info.title} ${spec.info.version}`;
+		document.getElementById("description").textContent = spec.info.description || "";
+		container.replaceChildren();
+		for (const path of Object.keys(spec.paths).sort()) {
+			for (const [method, operation] of Object.entries(spec.paths[path])) {
+				container.append(renderOperation(spec, path, method, operation));
+			}
+		}
+	} catch (e) {
+		container.replaceChildren(element("p", { class: "result error" }, String(e)));
+	}
+}
+
+main();
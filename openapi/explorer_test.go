@@ -0,0 +1,53 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/preston-wagner/go-resthelper/openapi"
+)
+
+func TestExplorerDisabledByDefault(t *testing.T) {
+	router := testRouter()
+	if route := openapi.MountExplorer(router, "/explorer/", openapi.Explorer{SpecURL: "/openapi.json"}); route != nil {
+		t.Error("expected no route for a disabled explorer")
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/explorer/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Error("expected 404, got", rec.Code)
+	}
+}
+
+func TestExplorer(t *testing.T) {
+	router := testRouter()
+	openapi.Mount(router, "/openapi.json", openapi.Info{Title: "Widgets", Version: "1.0"})
+	openapi.MountExplorer(router, "/explorer", openapi.Explorer{Enabled: true, SpecURL: "/openapi.json"})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/explorer/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatal("expected 200, got", rec.Code)
+	}
+	page := rec.Body.String()
+	if !strings.Contains(page, `data-spec-url="/openapi.json"`) || !strings.Contains(page, `src="/explorer/explorer.js"`) {
+		t.Error("expected the page to load the spec and its embedded script:", page)
+	}
+	if strings.Contains(page, "https://") {
+		t.Error("expected no external assets:", page)
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); csp != "default-src 'self'" {
+		t.Error("unexpected content security policy:", csp)
+	}
+
+	for asset, contentType := range map[string]string{"/explorer/explorer.js": "javascript", "/explorer/explorer.css": "text/css"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, asset, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Content-Type"), contentType) {
+			t.Error("expected", asset, "to be served as", contentType, "got", rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package openapi describes the routes registered through resthelper as an OpenAPI 3.1 document, reflecting their request and response types into JSON Schema
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+// Version is the OpenAPI version generated documents conform to
+const Version = "3.1.0"
+
+const (
+	jsonContentType    = "application/json"
+	problemContentType = "application/problem+json"
+	problemSchemaName  = "Problem"
+)
+
+// problemSchema describes the RFC 7807 bodies resthelper responds with when a client accepts them
+var problemSchema = Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"type":     {Type: "string", Format: "uri-reference"},
+		"title":    {Type: "string"},
+		"status":   {Type: "integer"},
+		"detail":   {Type: "string"},
+		"instance": {Type: "string", Format: "uri-reference"},
+	},
+	Required: []string{"type", "title", "status"},
+}
+
+// Generate describes every route registered on router through resthelper.Register or a resthelper.Group
+// routes registered directly on the router are left out, since nothing records their types
+func Generate(router *mux.Router, info Info) *Document {
+	gen := newSchemaGenerator()
+	// reserved first, so a request or response type that happens to be called Problem doesn't take its name
+	problem := problemSchema
+	gen.schemas[problemSchemaName] = &problem
+	document := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+	}
+	for _, route := range resthelper.Routes(router) {
+		path, operation := gen.operation(route)
+		item, ok := document.Paths[path]
+		if !ok {
+			item = PathItem{}
+			document.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+	document.Components.Schemas = gen.schemas
+	return document
+}
+
+func (gen *schemaGenerator) operation(route resthelper.RouteInfo) (string, *Operation) {
+	path, names, patterns := pathVariables(route.Path)
+	operation := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Responses:   map[string]*Response{},
+	}
+
+	params := []resthelper.Param{}
+	if route.RequestType != nil {
+		params = resthelper.Params(route.RequestType)
+	}
+	bound := map[string]bool{}
+	for _, param := range params {
+		rules, _ := resthelper.FieldValidationRules(param.Field)
+		parameter := &Parameter{
+			Name:     param.Name,
+			In:       param.In,
+			Required: param.In == "path" || rules.Required,
+			Schema:   gen.fieldSchema(param.Field, false),
+		}
+		if param.In == "path" {
+			bound[param.Name] = true
+			if pattern := patterns[param.Name]; pattern != "" && parameter.Schema.Pattern == "" && parameter.Schema.Type == "string" {
+				parameter.Schema.Pattern = anchored(pattern)
+			}
+		}
+		operation.Parameters = append(operation.Parameters, parameter)
+	}
+	// path variables the request type doesn't bind are still part of the path
+	for _, name := range names {
+		if !bound[name] {
+			schema := &Schema{Type: "string"}
+			if pattern := patterns[name]; pattern != "" {
+				schema.Pattern = anchored(pattern)
+			}
+			operation.Parameters = append(operation.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: schema})
+		}
+	}
+
+	if route.RequestType != nil && gen.hasBody(route.RequestType) {
+		operation.RequestBody = &RequestBody{
+			// DecodeRequest allows an empty body when the request type also binds parameters
+			Required: len(params) == 0,
+			Content:  map[string]*MediaType{jsonContentType: {Schema: gen.schemaFor(route.RequestType)}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+		if route.ResponseType == nil {
+			status = http.StatusNoContent
+		}
+	}
+	success := &Response{Description: http.StatusText(status)}
+	if route.ResponseType != nil && bodyAllowed(status) {
+		success.Content = map[string]*MediaType{jsonContentType: {Schema: gen.schemaFor(route.ResponseType)}}
+	}
+	operation.Responses[strconv.Itoa(status)] = success
+
+	errorStatuses := route.Errors
+	if route.RequestType != nil {
+		// every request type can fail to decode, bind or validate
+		errorStatuses = append([]int{http.StatusBadRequest, http.StatusUnprocessableEntity}, errorStatuses...)
+	}
+	for _, errorStatus := range errorStatuses {
+		operation.Responses[strconv.Itoa(errorStatus)] = &Response{
+			Description: http.StatusText(errorStatus),
+			Content:     map[string]*MediaType{problemContentType: {Schema: &Schema{Ref: componentPrefix + problemSchemaName}}},
+		}
+	}
+	return path, operation
+}
+
+// hasBody reports whether a request type has anything to decode from the body, rather than only binding parameters
+func (gen *schemaGenerator) hasBody(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || gen.specialSchema(t) != nil {
+		return true
+	}
+	return len(gen.objectSchema(t).Properties) > 0
+}
+
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// pathVariables strips the regular expressions from a mux path template like /users/{id:[0-9]+}, returning the OpenAPI path, the names of its variables in order, and the pattern of each variable that has one
+func pathVariables(template string) (string, []string, map[string]string) {
+	path := strings.Builder{}
+	names := []string{}
+	patterns := map[string]string{}
+	for {
+		start := strings.IndexByte(template, '{')
+		end := matchingBrace(template, start)
+		if start < 0 || end < 0 {
+			path.WriteString(template)
+			return path.String(), names, patterns
+		}
+		name, pattern, _ := strings.Cut(template[start+1:end], ":")
+		names = append(names, name)
+		if pattern != "" {
+			patterns[name] = pattern
+		}
+		path.WriteString(template[:start])
+		path.WriteString("{" + name + "}")
+		template = template[end+1:]
+	}
+}
+
+// matchingBrace finds the brace closing the one at start, allowing for braces in the variable's regular expression
+func matchingBrace(template string, start int) int {
+	if start < 0 {
+		return -1
+	}
+	depth := 0
+	for i := start; i < len(template); i++ {
+		switch template[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// anchored makes a mux variable pattern, which must match the whole variable, mean the same as a JSON Schema pattern, which may match anywhere
+func anchored(pattern string) string {
+	return "^(?:" + pattern + ")$"
+}
+
+// Handler serves the document generated for router's routes as json, wrapped with opts like any other resthelper route
+// the document is generated on the first request, by which time every route should have been registered
+func Handler(router *mux.Router, info Info, opts ...resthelper.Option) resthelper.DefaultMuxHandler {
+	var once sync.Once
+	var document *Document
+	return resthelper.JsonResponseWrapper(func(r *http.Request) (*Document, *resthelper.HttpError) {
+		once.Do(func() {
+			document = Generate(router, info)
+		})
+		return document, nil
+	}, opts...)
+}
+
+// Mount serves the document generated for router's routes from GET requests to path on the same router
+func Mount(router *mux.Router, path string, info Info, opts ...resthelper.Option) *mux.Route {
+	return router.HandleFunc(path, Handler(router, info, opts...)).Methods(http.MethodGet)
+}
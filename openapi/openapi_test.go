@@ -0,0 +1,165 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+	"github.com/preston-wagner/go-resthelper/openapi"
+)
+
+type testColor string
+
+func (testColor) EnumValues() []any {
+	return []any{"red", "green"}
+}
+
+type testAudit struct {
+	CreatedAt time.Time  `json:"createdAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+type testPage[T any] struct {
+	Items []T `json:"items"`
+	Next  *T  `json:"next"`
+}
+
+type testWidget struct {
+	testAudit
+	ID      int64             `path:"id" json:"-"`
+	Verbose bool              `query:"verbose" json:"-"`
+	Name    string            `json:"name" validate:"required,min=1,max=20" pattern:"^[a-z]+$"`
+	Color   testColor         `json:"color,omitempty"`
+	Size    int               `json:"size,omitempty" validate:"oneof=1 2 3"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Parent  *testWidget       `json:"parent,omitempty"`
+	Raw     []byte            `json:"raw,omitempty"`
+}
+
+func getWidget(r *http.Request) (testWidget, *resthelper.HttpError) {
+	return testWidget{}, nil
+}
+
+func updateWidget(r *http.Request, widget testWidget) (resthelper.Response[testPage[testWidget]], *resthelper.HttpError) {
+	return resthelper.NewResponse(http.StatusAccepted, testPage[testWidget]{}), nil
+}
+
+func deleteWidget(r *http.Request) *resthelper.HttpError {
+	return nil
+}
+
+func testRouter() *mux.Router {
+	router := mux.NewRouter()
+	widgets := resthelper.NewGroup(router, "/widgets", resthelper.GroupConfig{})
+	widgets.Get("/{id:[0-9]+}", resthelper.JsonResponse(getWidget), resthelper.OperationID("getWidget"), resthelper.Errors(http.StatusNotFound))
+	widgets.Put("/{id:[0-9]+}", resthelper.JsonToJson(updateWidget), resthelper.SuccessStatus(http.StatusAccepted), resthelper.Tags("widgets"))
+	widgets.Delete("/{id}", resthelper.NoContent(deleteWidget))
+	return router
+}
+
+func TestGenerate(t *testing.T) {
+	document := openapi.Generate(testRouter(), openapi.Info{Title: "Widgets", Version: "1.0"})
+
+	if document.OpenAPI != openapi.Version || document.Info.Title != "Widgets" {
+		t.Error("unexpected document header:", document.OpenAPI, document.Info)
+	}
+	item, ok := document.Paths["/widgets/{id}"]
+	if !ok {
+		t.Fatal("expected mux patterns to be stripped from paths, got", document.Paths)
+	}
+
+	get := item["get"]
+	if get.OperationID != "getWidget" || get.RequestBody != nil {
+		t.Error("unexpected get operation:", get)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "id" || !get.Parameters[0].Required || get.Parameters[0].Schema.Pattern != "^(?:[0-9]+)$" {
+		t.Error("expected an unbound path variable to be a string parameter with the mux pattern:", get.Parameters)
+	}
+	if get.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/testWidget" {
+		t.Error("expected the response to refer to the widget component:", get.Responses["200"])
+	}
+	if get.Responses["404"].Content["application/problem+json"].Schema.Ref != "#/components/schemas/Problem" {
+		t.Error("expected the documented error to be a problem:", get.Responses)
+	}
+
+	put := item["put"]
+	if len(put.Parameters) != 2 || put.Parameters[0].In != "path" || put.Parameters[0].Schema.Type != "integer" || put.Parameters[1].In != "query" || put.Parameters[1].Required {
+		t.Error("expected bound path and query parameters:", put.Parameters)
+	}
+	if put.RequestBody == nil || put.RequestBody.Required {
+		t.Error("expected an optional request body, since the request type binds parameters:", put.RequestBody)
+	}
+	if put.Responses["202"].Content["application/json"].Schema.Ref != "#/components/schemas/testPage_testWidget" {
+		t.Error("expected the Response[T] body type, named after its type argument:", put.Responses)
+	}
+	for _, status := range []string{"400", "422"} {
+		if _, ok := put.Responses[status]; !ok {
+			t.Error("expected an automatic", status, "response for a route with a request type")
+		}
+	}
+
+	remove := item["delete"]
+	if _, ok := remove.Responses["204"]; !ok || remove.Responses["204"].Content != nil {
+		t.Error("expected an empty 204 response:", remove.Responses)
+	}
+
+	widget := document.Components.Schemas["testWidget"]
+	if widget == nil {
+		t.Fatal("missing widget component")
+	}
+	expectedProperties := []string{"createdAt", "deletedAt", "name", "color", "size", "labels", "parent", "raw"}
+	if len(widget.Properties) != len(expectedProperties) {
+		t.Error("expected properties", expectedProperties, "got", widget.Properties)
+	}
+	if !reflect.DeepEqual(widget.Required, []string{"name", "createdAt"}) {
+		t.Error("unexpected required properties:", widget.Required)
+	}
+	name := widget.Properties["name"]
+	if *name.MinLength != 1 || *name.MaxLength != 20 || name.Pattern != "^[a-z]+$" {
+		t.Error("expected validation rules on name:", name)
+	}
+	if !reflect.DeepEqual(widget.Properties["size"].Enum, []any{1.0, 2.0, 3.0}) {
+		t.Error("expected oneof values as numbers:", widget.Properties["size"].Enum)
+	}
+	if widget.Properties["createdAt"].Format != "date-time" || !reflect.DeepEqual(widget.Properties["deletedAt"].Type, []string{"string", "null"}) {
+		t.Error("expected times as nullable date-time strings:", widget.Properties["createdAt"], widget.Properties["deletedAt"])
+	}
+	if widget.Properties["labels"].AdditionalProperties.(*openapi.Schema).Type != "string" {
+		t.Error("expected maps as objects with additionalProperties:", widget.Properties["labels"])
+	}
+	if parent := widget.Properties["parent"]; len(parent.AnyOf) != 2 || parent.AnyOf[0].Ref != "#/components/schemas/testWidget" {
+		t.Error("expected a recursive, nullable reference:", parent)
+	}
+	if widget.Properties["raw"].ContentEncoding != "base64" {
+		t.Error("expected byte slices as base64 strings:", widget.Properties["raw"])
+	}
+	if color := document.Components.Schemas["testColor"]; color == nil || !reflect.DeepEqual(color.Enum, []any{"red", "green"}) {
+		t.Error("expected an enum component:", color)
+	}
+}
+
+func TestMount(t *testing.T) {
+	router := testRouter()
+	openapi.Mount(router, "/openapi.json", openapi.Info{Title: "Widgets", Version: "1.0"})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatal("expected 200, got", rec.Code)
+	}
+	document := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if document["openapi"] != "3.1.0" {
+		t.Error("unexpected document:", document)
+	}
+	if paths := document["paths"].(map[string]any); len(paths) != 1 {
+		t.Error("expected only the widget routes, not the spec endpoint itself:", paths)
+	}
+}
@@ -0,0 +1,349 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+// Enum can be implemented by named types with a fixed set of values, which are then listed in their schema
+type Enum interface {
+	EnumValues() []any
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	enumType          = reflect.TypeFor[Enum]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+const componentPrefix = "#/components/schemas/"
+
+// schemaGenerator reflects Go types into schemas, collecting named structs and enums as components so they are described once and referenced everywhere else
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// schemaFor describes how encoding/json represents values of type t
+func (gen *schemaGenerator) schemaFor(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return nullable(gen.schemaFor(t.Elem()))
+	}
+	if schema := gen.specialSchema(t); schema != nil {
+		return schema
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes byte slices as base64 strings
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: gen.schemaFor(t.Elem())}
+	case reflect.Array:
+		length := t.Len()
+		return &Schema{Type: "array", Items: gen.schemaFor(t.Elem()), MinItems: &length, MaxItems: &length}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: gen.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return gen.structSchema(t)
+	default:
+		// interfaces, and anything else encoding/json can't say much about ahead of time
+		return &Schema{}
+	}
+}
+
+// specialSchema describes the types encoding/json doesn't treat according to their kind, or returns nil
+func (gen *schemaGenerator) specialSchema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case implements(t, enumType) && t.Name() != "":
+		return gen.enumSchema(t)
+	case implements(t, jsonMarshalerType):
+		return &Schema{}
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+	return nil
+}
+
+// implements reports whether values of type t, or pointers to them, implement iface
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+func (gen *schemaGenerator) enumSchema(t reflect.Type) *Schema {
+	return gen.component(t, func() *Schema {
+		enum := reflect.New(t).Interface().(Enum)
+		schema := &Schema{}
+		if t.Kind() != reflect.Struct {
+			schema = gen.schemaFor(kindType(t.Kind()))
+		}
+		schema.Enum = enum.EnumValues()
+		return schema
+	})
+}
+
+// kindType returns an unnamed type of the given kind, for describing named types by their kind alone
+func kindType(kind reflect.Kind) reflect.Type {
+	switch kind {
+	case reflect.Bool:
+		return reflect.TypeFor[bool]()
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return reflect.TypeFor[int32]()
+	case reflect.Int, reflect.Int64:
+		return reflect.TypeFor[int64]()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.TypeFor[uint64]()
+	case reflect.Float32, reflect.Float64:
+		return reflect.TypeFor[float64]()
+	default:
+		return reflect.TypeFor[string]()
+	}
+}
+
+func (gen *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		// anonymous structs are described inline
+		return gen.objectSchema(t)
+	}
+	return gen.component(t, func() *Schema {
+		return gen.objectSchema(t)
+	})
+}
+
+// component registers the schema build returns under a name derived from t, the first time t is seen, and returns a reference to it
+func (gen *schemaGenerator) component(t reflect.Type, build func() *Schema) *Schema {
+	name, ok := gen.names[t]
+	if !ok {
+		name = gen.componentName(t)
+		gen.names[t] = name
+		// reserve the name before building, so recursive types refer back to it instead of recursing forever
+		gen.schemas[name] = &Schema{}
+		*gen.schemas[name] = *build()
+	}
+	return &Schema{Ref: componentPrefix + name}
+}
+
+// packagePath matches the import path qualifying type names, like the "github.com/org/app." of "github.com/org/app.User"
+var packagePath = regexp.MustCompile(`[\w./-]+\.`)
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// componentName names t's component after its Go name, turning generic instantiations like Page[github.com/org/app.User] into Page_User
+// types from different packages sharing a name are told apart by their package name, then a number
+func (gen *schemaGenerator) componentName(t reflect.Type) string {
+	name := packagePath.ReplaceAllString(t.Name(), "")
+	name = strings.Trim(nonIdentifier.ReplaceAllString(name, "_"), "_")
+	if _, taken := gen.schemas[name]; !taken {
+		return name
+	}
+	name = path.Base(t.PkgPath()) + "_" + name
+	candidate := name
+	for i := 2; ; i++ {
+		if _, taken := gen.schemas[candidate]; !taken {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+func (gen *schemaGenerator) objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	gen.addFields(t, schema)
+	return schema
+}
+
+// addFields adds the fields encoding/json would encode for struct type t to schema, promoting the fields of embedded structs unless a shallower field has the same name
+func (gen *schemaGenerator) addFields(t reflect.Type, schema *Schema) {
+	embedded := []reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				embedded = append(embedded, fieldType)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, exists := schema.Properties[name]; exists {
+			continue
+		}
+		schema.Properties[name] = gen.fieldSchema(field, hasOption(options, "string"))
+		rules, _ := resthelper.FieldValidationRules(field)
+		optional := hasOption(options, "omitempty") || hasOption(options, "omitzero") || field.Type.Kind() == reflect.Pointer
+		if rules.Required || !optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	for _, embeddedType := range embedded {
+		gen.addFields(embeddedType, schema)
+	}
+}
+
+func hasOption(options string, option string) bool {
+	for _, candidate := range strings.Split(options, ",") {
+		if candidate == option {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldSchema describes a struct field, including the constraints of its validate and pattern tags
+// asString is set for fields tagged with the json ",string" option, which quotes numbers and bools
+func (gen *schemaGenerator) fieldSchema(field reflect.StructField, asString bool) *Schema {
+	fieldType := field.Type
+	isPointer := false
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+		isPointer = true
+	}
+	schema := gen.schemaFor(fieldType)
+	if asString && schema.Type != "string" && schema.Type != nil && schema.Ref == "" {
+		schema = &Schema{Type: "string"}
+	}
+	if rules, err := resthelper.FieldValidationRules(field); err == nil {
+		applyRules(schema, fieldType.Kind(), rules)
+	}
+	if isPointer {
+		schema = nullable(schema)
+	}
+	return schema
+}
+
+// applyRules adds the constraints ValidateRequest enforces to schema, where JSON Schema can express them
+func applyRules(schema *Schema, kind reflect.Kind, rules resthelper.ValidationRules) {
+	if schema.Ref != "" {
+		return
+	}
+	switch kind {
+	case reflect.String:
+		schema.MinLength, schema.MaxLength = intLimit(rules.Min), intLimit(rules.Max)
+	case reflect.Slice, reflect.Array:
+		if schema.Type == "string" {
+			// byte slices; their length limits apply to the decoded bytes, not the base64 string
+			break
+		}
+		schema.MinItems, schema.MaxItems = intLimit(rules.Min), intLimit(rules.Max)
+	case reflect.Map:
+		schema.MinProperties, schema.MaxProperties = intLimit(rules.Min), intLimit(rules.Max)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if rules.Min != nil {
+			schema.Minimum = rules.Min
+		}
+		if rules.Max != nil {
+			schema.Maximum = rules.Max
+		}
+	}
+	for _, allowed := range rules.OneOf {
+		schema.Enum = append(schema.Enum, enumValue(kind, allowed))
+	}
+	if rules.Pattern != nil && kind == reflect.String {
+		schema.Pattern = rules.Pattern.String()
+	}
+}
+
+func intLimit(limit *float64) *int {
+	if limit == nil {
+		return nil
+	}
+	value := int(*limit)
+	return &value
+}
+
+// enumValue converts a oneof rule's value to the json type of the field it constrains
+func enumValue(kind reflect.Kind, value string) any {
+	switch kind {
+	case reflect.Bool:
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return value
+}
+
+// nullable extends schema to also allow null, as encoding/json writes for nil pointers
+func nullable(schema *Schema) *Schema {
+	switch typeName := schema.Type.(type) {
+	case string:
+		schema.Type = []string{typeName, "null"}
+		if len(schema.Enum) > 0 {
+			schema.Enum = append(schema.Enum, nil)
+		}
+		return schema
+	case nil:
+		if schema.Ref == "" && len(schema.AnyOf) == 0 {
+			// an empty schema already allows anything, including null
+			return schema
+		}
+		if len(schema.AnyOf) > 0 && isNull(schema.AnyOf[len(schema.AnyOf)-1]) {
+			return schema
+		}
+		return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+	default:
+		// already nullable
+		return schema
+	}
+}
+
+func isNull(schema *Schema) bool {
+	return schema.Type == "null"
+}
@@ -0,0 +1,86 @@
+package resthelper
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Option customizes the behavior of a single wrapped handler
+type Option func(*config)
+
+type config struct {
+	cors           *CORSPolicy
+	strictDecoding bool
+	limits         Limits
+	codecs         *CodecRegistry
+
+	marshalErrorHooks []MarshalErrorHook
+	startHooks        []RequestStartHook
+	responseHooks     []ResponseHook
+	runHook           func(func())
+	interceptors      []Interceptor[any]
+
+	logger    *slog.Logger
+	accessLog bool
+	logAttrs  []LogAttrsFunc
+
+	requestIDHeader   string
+	generateRequestID func(*http.Request) string
+
+	tracer Tracer
+
+	panicHandler PanicHandler
+
+	timeout    time.Duration
+	timeoutErr *HttpError
+}
+
+var (
+	defaultOptionsLock sync.RWMutex
+	defaultOptions     []Option
+)
+
+// SetDefaultOptions sets options applied to every wrapper created afterwards (and to DecodeRequest calls outside of a wrapper), before the wrapper's own options
+// it is meant to be called during startup, before routes are registered; wrappers already created keep the defaults they were created with
+func SetDefaultOptions(opts ...Option) {
+	defaultOptionsLock.Lock()
+	defer defaultOptionsLock.Unlock()
+	defaultOptions = slices.Clone(opts)
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		cors:    DefaultCORSPolicy(),
+		codecs:  DefaultCodecRegistry,
+		runHook: runHookAsync,
+
+		panicHandler: DefaultPanicHandler,
+		timeoutErr:   defaultTimeoutError(),
+
+		requestIDHeader:   DefaultRequestIDHeader,
+		generateRequestID: generateRandomRequestID,
+	}
+	defaultOptionsLock.RLock()
+	for _, opt := range defaultOptions {
+		opt(cfg)
+	}
+	defaultOptionsLock.RUnlock()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+type configKey struct{}
+
+// configFromContext returns the config of the wrapper serving the current request, so helpers like DecodeRequest can follow per-route options
+func configFromContext(ctx context.Context) *config {
+	if cfg, ok := ctx.Value(configKey{}).(*config); ok {
+		return cfg
+	}
+	return newConfig(nil)
+}
@@ -0,0 +1,41 @@
+package resthelper
+
+import (
+	"net/http"
+)
+
+// PanicHandler decides how to answer a request whose handler or hooks panicked, given the recovered value and the stack of the goroutine that panicked
+// the error it returns is sent to the client, unless the handler had already started writing its response, in which case what was written is left alone
+// returning nil sends nothing and aborts the response as a panic with http.ErrAbortHandler does, so the client can tell it is incomplete
+type PanicHandler func(r *http.Request, recovered any, stack []byte) *HttpError
+
+// DefaultPanicHandler answers every panic with a plain 500 Internal Server Error, keeping the details out of the response
+func DefaultPanicHandler(r *http.Request, recovered any, stack []byte) *HttpError {
+	return NewHttpErrF(http.StatusInternalServerError, "goroutine panic")
+}
+
+// WithPanicHandler replaces DefaultPanicHandler; panics are still logged (see WithLogger) and reported to ResponseHooks in ResponseInfo.Panic
+// panics with http.ErrAbortHandler skip the PanicHandler and the log, and are re-raised once the hooks have run so the server aborts the response quietly
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(cfg *config) {
+		cfg.panicHandler = handler
+	}
+}
+
+// recoverPanic answers a recovered panic, reporting whether the response should be aborted by re-panicking with http.ErrAbortHandler
+func (cfg *config) recoverPanic(rec *responseRecorder, recovered any, stack []byte) bool {
+	if recovered == http.ErrAbortHandler {
+		return true
+	}
+	httpErr := cfg.panicHandler(rec.request, recovered, stack)
+	if httpErr == nil {
+		return true
+	}
+	if rec.wroteHeader {
+		// too late to change the status, but PostResponseHooks should still hear that the request failed
+		rec.err = httpErr
+		return false
+	}
+	respondWithError(rec, rec.request, httpErr)
+	return false
+}
@@ -0,0 +1,130 @@
+package resthelper_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func TestPanicHandler(t *testing.T) {
+	var handledRequest *http.Request
+	var handledStack []byte
+	infos := make(chan resthelper.ResponseInfo, 1)
+	postErrs := make(chan *resthelper.HttpError, 1)
+	handler := resthelper.NoContentWrapperWithHooks(nil, func(r *http.Request) *resthelper.HttpError {
+		panic("oh no")
+	}, []resthelper.PostResponseHook{func(err *resthelper.HttpError, status int) {
+		postErrs <- err
+	}}, resthelper.WithSynchronousHooks(), resthelper.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		resthelper.WithResponseHooks(func(info resthelper.ResponseInfo) {
+			infos <- info
+		}),
+		resthelper.WithPanicHandler(func(r *http.Request, recovered any, stack []byte) *resthelper.HttpError {
+			handledRequest, handledStack = r, stack
+			return resthelper.NewHttpErrF(http.StatusServiceUnavailable, "recovered from %v", recovered)
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodDelete, "/things/1", nil))
+	if rec.Code != http.StatusServiceUnavailable || plainTextError(t, rec) != "recovered from oh no" {
+		t.Error("expected the panic handler's error, got", rec.Code, rec.Body.String())
+	}
+	if handledRequest == nil || handledRequest.URL.Path != "/things/1" {
+		t.Error("expected the panic handler to receive the request")
+	}
+	if !strings.Contains(string(handledStack), "panic_test.go") {
+		t.Error("expected the stack to include the panicking frame:", string(handledStack))
+	}
+	if info := <-infos; info.Panic != "oh no" || !bytes.Equal(info.PanicStack, handledStack) || info.Status != http.StatusServiceUnavailable {
+		t.Error("expected response hooks to see the panic, got", info)
+	}
+	if err := <-postErrs; err == nil || err.Status != http.StatusServiceUnavailable {
+		t.Error("expected post response hooks to see the error, got", err)
+	}
+}
+
+// failingWriter panics part way through writing a body, after the status has been sent
+type failingWriter struct {
+	*httptest.ResponseRecorder
+	headerWrites int
+}
+
+func (w *failingWriter) WriteHeader(status int) {
+	w.headerWrites++
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func (w *failingWriter) Write(data []byte) (int, error) {
+	if !bytes.Equal(data, []byte("error")) {
+		panic("write failed")
+	}
+	return w.ResponseRecorder.Write(data)
+}
+
+func TestPanicAfterWriteHeader(t *testing.T) {
+	postErrs := make(chan *resthelper.HttpError, 1)
+	handler := resthelper.JsonResponseWrapperWithHooks(nil, func(r *http.Request) (string, *resthelper.HttpError) {
+		return "hello", nil
+	}, []resthelper.PostResponseHook{func(err *resthelper.HttpError, status int) {
+		postErrs <- err
+	}}, resthelper.WithSynchronousHooks(), resthelper.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		resthelper.WithPanicHandler(func(r *http.Request, recovered any, stack []byte) *resthelper.HttpError {
+			return resthelper.NewHttpErrF(http.StatusInternalServerError, "error")
+		}),
+	)
+
+	w := &failingWriter{ResponseRecorder: httptest.NewRecorder()}
+	handler(w, httptest.NewRequest(http.MethodGet, "/greeting", nil))
+	if w.headerWrites != 1 || w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Error("expected the response already started to be left alone, got", w.headerWrites, w.Code, w.Body.String())
+	}
+	if err := <-postErrs; err == nil {
+		t.Error("expected post response hooks to hear about the panic")
+	}
+}
+
+func TestAbortHandler(t *testing.T) {
+	for name, opts := range map[string][]resthelper.Option{
+		"ErrAbortHandler": nil,
+		"nil from PanicHandler": {resthelper.WithPanicHandler(func(r *http.Request, recovered any, stack []byte) *resthelper.HttpError {
+			return nil
+		})},
+	} {
+		infos := make(chan resthelper.ResponseInfo, 1)
+		opts = append(opts, resthelper.WithSynchronousHooks(), resthelper.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+			resthelper.WithResponseHooks(func(info resthelper.ResponseInfo) {
+				infos <- info
+			}))
+		handler := resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+			if name == "ErrAbortHandler" {
+				panic(http.ErrAbortHandler)
+			}
+			panic("oh no")
+		}, opts...)
+
+		rec := httptest.NewRecorder()
+		recovered := func() (recovered any) {
+			defer func() {
+				recovered = recover()
+			}()
+			handler(rec, httptest.NewRequest(http.MethodDelete, "/things/1", nil))
+			return nil
+		}()
+		if recovered != http.ErrAbortHandler {
+			t.Error(name, "expected the wrapper to abort the response, got", recovered)
+		}
+		if rec.Body.Len() != 0 {
+			t.Error(name, "expected no response body, got", rec.Body.String())
+		}
+		if info := <-infos; info.Panic == nil {
+			t.Error(name, "expected response hooks to run before aborting, got", info)
+		}
+	}
+}
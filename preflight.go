@@ -0,0 +1,105 @@
+package resthelper
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// HandlePreflight registers an OPTIONS responder on router for every path served by a resthelper wrapper, so that routes restricted with .Methods() still answer CORS preflight requests
+// it covers routes added with router.HandleFunc or router.Handle as well as those registered with Register, Route.Register or a Group, but not wrappers hidden inside other middleware
+// a preflight is only answered where one of those routes would serve the request it precedes, so host, scheme, header and query matchers are honored
+// Access-Control-Allow-Methods is computed from the methods actually registered for each path; call it once, after all other routes have been added
+func HandlePreflight(router *mux.Router) error {
+	templates := []string{}
+	routesByTemplate := map[string][]*mux.Route{}
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		handler := wrappedHandlerOf(route.GetHandler())
+		if handler == nil || handler.cfg.cors == nil {
+			return nil
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		if _, err := route.GetMethods(); err != nil {
+			// routes without a method matcher already receive OPTIONS requests, and the wrapper answers preflights itself
+			return nil
+		}
+		if _, ok := routesByTemplate[template]; !ok {
+			templates = append(templates, template)
+		}
+		routesByTemplate[template] = append(routesByTemplate[template], route)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, template := range templates {
+		preflight := preflightRoutes(routesByTemplate[template])
+		router.NewRoute().MatcherFunc(preflight.match).Handler(preflight)
+	}
+	return nil
+}
+
+// preflightRoutes answers preflights for the routes sharing a path template
+type preflightRoutes []*mux.Route
+
+// matching returns the routes that would serve the request a preflight is for, by matching it against each route as if it had been made with one of the route's methods
+func (routes preflightRoutes) matching(r *http.Request) []*mux.Route {
+	if r.Method != http.MethodOptions {
+		return nil
+	}
+	matched := []*mux.Route{}
+	for _, route := range routes {
+		methods, _ := route.GetMethods()
+		if len(methods) == 0 {
+			continue
+		}
+		probe := r.WithContext(r.Context())
+		probe.Method = methods[0]
+		if route.Match(probe, &mux.RouteMatch{}) {
+			matched = append(matched, route)
+		}
+	}
+	return matched
+}
+
+func (routes preflightRoutes) match(r *http.Request, match *mux.RouteMatch) bool {
+	return len(routes.matching(r)) > 0
+}
+
+func (routes preflightRoutes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	matched := routes.matching(r)
+	methods := []string{}
+	for _, route := range matched {
+		routeMethods, _ := route.GetMethods()
+		for _, method := range routeMethods {
+			methods = appendUnique(methods, method)
+		}
+	}
+	policy := wrappedHandlerOf(matched[0].GetHandler()).cfg.cors
+	respondToPreflight(w, r, policy, appendUnique(methods, http.MethodOptions))
+}
+
+// respondToPreflight answers an OPTIONS request with the policy's CORS headers; methods overrides the policy's AllowedMethods when known
+func respondToPreflight(w http.ResponseWriter, r *http.Request, policy *CORSPolicy, methods []string) {
+	policy.writeHeaders(w, r)
+	if len(methods) > 0 {
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,93 @@
+package resthelper_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func preflightRequest(router *mux.Router, path, method string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("OPTIONS", path, nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", method)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlePreflight(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{id}", resthelper.JsonToJsonWrapper(testJsonHandler)).Methods("POST")
+	router.HandleFunc("/items/{id}", resthelper.NoContentWrapper(testCorsHandler)).Methods("DELETE")
+	router.HandleFunc("/private/", resthelper.NoContentWrapper(testCorsHandler, resthelper.WithoutCORS())).Methods("POST")
+	router.HandleFunc("/plain/", func(w http.ResponseWriter, r *http.Request) {}).Methods("POST")
+
+	if rec := preflightRequest(router, "/items/7", "DELETE"); rec.Code != http.StatusMethodNotAllowed {
+		t.Error("expected preflight to be rejected before HandlePreflight, got", rec.Code)
+	}
+
+	if err := resthelper.HandlePreflight(router); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := preflightRequest(router, "/items/7", "DELETE")
+	if rec.Code != http.StatusNoContent {
+		t.Error("expected", http.StatusNoContent, "got", rec.Code)
+	}
+	if allowMethods := rec.Header().Get("Access-Control-Allow-Methods"); allowMethods != "POST, DELETE, OPTIONS" {
+		t.Error("unexpected Access-Control-Allow-Methods:", allowMethods)
+	}
+	if allowOrigin := rec.Header().Get("Access-Control-Allow-Origin"); allowOrigin != "*" {
+		t.Error("unexpected Access-Control-Allow-Origin:", allowOrigin)
+	}
+
+	for _, path := range []string{"/private/", "/plain/"} {
+		if rec := preflightRequest(router, path, "POST"); rec.Code != http.StatusMethodNotAllowed {
+			t.Error("expected no preflight responder for", path, "got", rec.Code)
+		}
+	}
+}
+
+func TestPreflightWithoutMethodMatcher(t *testing.T) {
+	called := false
+	router := mux.NewRouter()
+	router.HandleFunc("/any/", resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		called = true
+		return nil
+	}))
+
+	rec := preflightRequest(router, "/any/", "PUT")
+	if rec.Code != http.StatusNoContent {
+		t.Error("expected", http.StatusNoContent, "got", rec.Code)
+	}
+	if called {
+		t.Error("handler should not run for preflight requests")
+	}
+}
+
+func TestPreflightKeepsMatchers(t *testing.T) {
+	router := mux.NewRouter()
+	api := resthelper.NewGroup(router.Host("api.example.com").Subrouter(), "/api", resthelper.GroupConfig{})
+	api.Delete("/items/{id}", resthelper.NoContent(testCorsHandler))
+	resthelper.Register(router, "PUT", "/api/items/{id}", resthelper.NoContent(testCorsHandler))
+	if err := resthelper.HandlePreflight(router); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("OPTIONS", "http://api.example.com/api/items/7", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Methods") != "DELETE, PUT, OPTIONS" {
+		t.Error("expected the preflight on the group's host to be answered, got", rec.Code, rec.Header())
+	}
+
+	rec = preflightRequest(router, "/api/items/7", "PUT")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Methods") != "PUT, OPTIONS" {
+		t.Error("expected only the methods served on other hosts, got", rec.Code, rec.Header())
+	}
+}
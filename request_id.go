@@ -0,0 +1,70 @@
+package resthelper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// DefaultRequestIDHeader is the header request IDs are read from and echoed in unless WithRequestIDHeader says otherwise
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// the longest inbound request ID that is trusted; anything longer, or containing anything but printable ascii, is replaced
+const maxRequestIDLength = 128
+
+var requestIDKey = NewContextKey[string]("request ID")
+
+// RequestID returns the ID the serving wrapper assigned to the request ctx belongs to, or "" outside of a wrapper
+func RequestID(ctx context.Context) string {
+	id, _ := requestIDKey.Value(ctx)
+	return id
+}
+
+// WithRequestIDHeader sets the header request IDs are taken from, when the client (or a proxy in front of the service) sends one, and echoed in
+// an empty header turns request IDs off entirely
+func WithRequestIDHeader(header string) Option {
+	return func(cfg *config) {
+		cfg.requestIDHeader = header
+	}
+}
+
+// WithRequestIDGenerator replaces the random IDs given to requests that arrive without one
+func WithRequestIDGenerator(generate func(r *http.Request) string) Option {
+	return func(cfg *config) {
+		cfg.generateRequestID = generate
+	}
+}
+
+// assignRequestID takes the request's ID from its header, or generates one, echoing it in the response and storing it in the request's context
+func (cfg *config) assignRequestID(w http.ResponseWriter, r *http.Request) *http.Request {
+	if cfg.requestIDHeader == "" {
+		return r
+	}
+	id := r.Header.Get(cfg.requestIDHeader)
+	if !validRequestID(id) {
+		id = cfg.generateRequestID(r)
+	}
+	w.Header().Set(cfg.requestIDHeader, id)
+	return requestIDKey.WithRequestValue(r, id)
+}
+
+// validRequestID guards against clients filling logs and headers with arbitrary data
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRandomRequestID returns 128 random bits as hex
+func generateRandomRequestID(r *http.Request) string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,161 @@
+package resthelper_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func TestRequestIDs(t *testing.T) {
+	var handlerID string
+	infos := make(chan resthelper.ResponseInfo, 1)
+	handler := resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		handlerID = resthelper.RequestID(r.Context())
+		return resthelper.NewHttpErrF(http.StatusNotFound, "no such thing")
+	}, resthelper.WithSynchronousHooks(), resthelper.WithResponseHooks(func(info resthelper.ResponseInfo) {
+		infos <- info
+	}))
+
+	for _, inbound := range []string{"", "abc-123", "not valid", strings.Repeat("x", 200)} {
+		req := httptest.NewRequest(http.MethodDelete, "/things/1", nil)
+		req.Header.Set("Accept", "application/problem+json")
+		if inbound != "" {
+			req.Header.Set("X-Request-ID", inbound)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		id := rec.Header().Get("X-Request-ID")
+		if inbound == "abc-123" && id != inbound {
+			t.Error("expected a valid inbound ID to be kept, got", id)
+		}
+		if inbound != "abc-123" && (len(id) != 32 || id == inbound) {
+			t.Error("expected a generated ID for inbound", inbound, "got", id)
+		}
+		if handlerID != id {
+			t.Error("expected the handler to see the echoed ID", id, "got", handlerID)
+		}
+		problem := map[string]any{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err)
+		}
+		if problem["requestId"] != id {
+			t.Error("expected the ID in the problem body:", problem)
+		}
+		if info := <-infos; info.RequestID != id {
+			t.Error("expected response hooks to see the ID, got", info.RequestID)
+		}
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	handler := resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		return nil
+	}, resthelper.WithRequestIDHeader("X-Correlation-ID"), resthelper.WithRequestIDGenerator(func(r *http.Request) string {
+		return "generated"
+	}))
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodDelete, "/things/1", nil))
+	if id := rec.Header().Get("X-Correlation-ID"); id != "generated" || rec.Header().Get("X-Request-ID") != "" {
+		t.Error("expected the configured header and generator, got", rec.Header())
+	}
+
+	handler = resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		if resthelper.RequestID(r.Context()) != "" {
+			t.Error("expected no request ID when turned off")
+		}
+		return nil
+	}, resthelper.WithRequestIDHeader(""))
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodDelete, "/things/1", nil))
+	if len(rec.Header().Values("X-Request-ID")) != 0 {
+		t.Error("expected no request ID header when turned off")
+	}
+}
+
+func TestClientPropagatesRequestID(t *testing.T) {
+	var downstreamID string
+	downstream := mux.NewRouter()
+	testCreateItem.Register(downstream, func(r *http.Request, input testJsonStruct) (resthelper.Response[testJsonStruct], *resthelper.HttpError) {
+		downstreamID = resthelper.RequestID(r.Context())
+		return resthelper.NewResponse(http.StatusOK, input), nil
+	})
+	server := httptest.NewServer(downstream)
+	defer server.Close()
+
+	upstream := resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		_, err := testCreateItem.Call(r.Context(), resthelper.NewClient(server.URL), testJsonStruct{Name: "Steve"})
+		if err != nil {
+			return resthelper.NewHttpErr(http.StatusBadGateway, err)
+		}
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-Request-ID", "upstream-1")
+	rec := httptest.NewRecorder()
+	upstream(rec, req)
+	if rec.Code != http.StatusNoContent || downstreamID != "upstream-1" {
+		t.Error("expected the downstream service to receive the upstream request ID, got", rec.Code, downstreamID)
+	}
+}
+
+// plainTextError returns the message of a plain text error body, checking that the request ID at its end matches the response header
+func plainTextError(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	message, id, found := strings.Cut(rec.Body.String(), "\nrequest ID: ")
+	if !found || id != rec.Header().Get("X-Request-ID") {
+		t.Error("expected the request ID at the end of the error body, got", rec.Body.String())
+	}
+	return message
+}
+
+func TestPlainTextErrorRequestID(t *testing.T) {
+	handler := resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		return resthelper.NewHttpErrF(http.StatusConflict, "Steve already exists")
+	})
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	httpErr := resthelper.ReadHttpError(resp)
+	if httpErr.Error() != "Steve already exists" || httpErr.Extensions["requestId"] != resp.Header.Get("X-Request-ID") {
+		t.Error("expected the client to split the request ID from the message, got", httpErr.Error(), httpErr.Extensions)
+	}
+	if exposed := resp.Header.Get("Access-Control-Expose-Headers"); exposed != "X-Request-ID" {
+		t.Error("expected browsers to be allowed to read the request ID, got", exposed)
+	}
+}
+
+func TestClientPropagatesCustomRequestIDHeader(t *testing.T) {
+	var downstreamID string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstreamID = r.Header.Get("X-Correlation-ID")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Name":"Steve"}`))
+	}))
+	defer downstream.Close()
+
+	upstream := resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		_, err := testCreateItem.Call(r.Context(), resthelper.NewClient(downstream.URL), testJsonStruct{Name: "Steve"})
+		if err != nil {
+			return resthelper.NewHttpErr(http.StatusBadGateway, err)
+		}
+		return nil
+	}, resthelper.WithRequestIDHeader("X-Correlation-ID"))
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-Correlation-ID", "upstream-1")
+	rec := httptest.NewRecorder()
+	upstream(rec, req)
+	if rec.Code != http.StatusNoContent || downstreamID != "upstream-1" {
+		t.Error("expected the downstream service to receive the ID in the configured header, got", rec.Code, downstreamID)
+	}
+}
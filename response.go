@@ -0,0 +1,103 @@
+package resthelper
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Response lets a JsonResponseHandler choose the status code, headers and cookies of a successful response along with its body
+// use it as the handler's return type, e.g. JsonResponseHandler[Response[User]]; the wrapper still guarantees every path produces a response
+// handlers may return a *Response instead, in which case a nil one is sent like an empty Response
+type Response[T any] struct {
+	Status  int // defaults to 200 OK
+	Headers http.Header
+	Cookies []*http.Cookie
+	Body    T
+}
+
+// NewResponse creates a Response with the given status and body
+func NewResponse[T any](status int, body T) Response[T] {
+	return Response[T]{
+		Status: status,
+		Body:   body,
+	}
+}
+
+// Created creates a 201 Created Response pointing at the new resource's location
+func Created[T any](location string, body T) Response[T] {
+	return Response[T]{
+		Status:  http.StatusCreated,
+		Headers: http.Header{"Location": []string{location}},
+		Body:    body,
+	}
+}
+
+// Accepted creates a 202 Accepted Response, for requests that will be processed asynchronously
+func Accepted[T any](body T) Response[T] {
+	return NewResponse(http.StatusAccepted, body)
+}
+
+// responseEnvelope is implemented by every Response[T], so wrappers can tell a Response apart from a plain body without knowing T
+type responseEnvelope interface {
+	unwrapResponse() (int, http.Header, []*http.Cookie, any)
+	bodyType() reflect.Type
+}
+
+func (response Response[T]) unwrapResponse() (int, http.Header, []*http.Cookie, any) {
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return status, response.Headers, response.Cookies, response.Body
+}
+
+func (response Response[T]) bodyType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// responseReceiver is implemented by pointers to every Response[T], so clients can fill one in without knowing T
+type responseReceiver interface {
+	receiveResponse(status int, headers http.Header, cookies []*http.Cookie) any
+}
+
+// receiveResponse records the status, headers and cookies of a received response, returning the target to decode its body into
+func (response *Response[T]) receiveResponse(status int, headers http.Header, cookies []*http.Cookie) any {
+	response.Status = status
+	response.Headers = headers
+	response.Cookies = cookies
+	return &response.Body
+}
+
+// responseBodyType is the type a handler returning T actually serializes, looking through Response[T] and *Response[T]
+func responseBodyType[T any]() reflect.Type {
+	t := reflect.TypeFor[T]()
+	if envelope, ok := zeroEnvelope(t); ok {
+		return envelope.bodyType()
+	}
+	return t
+}
+
+// zeroEnvelope returns the zero Response of type t, or of the type t points to, since the zero *Response is nil and can't have its methods called
+func zeroEnvelope(t reflect.Type) (responseEnvelope, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	envelope, ok := reflect.Zero(t).Interface().(responseEnvelope)
+	return envelope, ok
+}
+
+// unwrapPayload separates the status, headers and cookies of a successful response from its body; plain bodies are sent as 200 OK
+func unwrapPayload(payload any) (int, http.Header, []*http.Cookie, any) {
+	if envelope, ok := payload.(responseEnvelope); ok {
+		if value := reflect.ValueOf(payload); value.Kind() == reflect.Pointer && value.IsNil() {
+			envelope, _ = zeroEnvelope(value.Type())
+		}
+		return envelope.unwrapResponse()
+	}
+	return http.StatusOK, nil, nil, payload
+}
+
+// bodyAllowed reports whether a response with the given status may include a body
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
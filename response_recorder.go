@@ -0,0 +1,63 @@
+package resthelper
+
+import (
+	"net/http"
+)
+
+// responseRecorder wraps the ResponseWriter given to a wrapped handler, keeping track of what was written so it can be reported to ResponseHooks
+type responseRecorder struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int64
+	wroteHeader  bool
+	request      *http.Request // the request as last seen by the wrapper, including anything added by ContextHooks
+	err          *HttpError
+	finishers    []func(ResponseInfo) // returned by RequestStartHooks
+	span         Span
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		// the status can't change once sent, so later calls are dropped rather than passed on for the server to complain about
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	n, err := rec.ResponseWriter.Write(data)
+	rec.bytesWritten += int64(n)
+	return n, err
+}
+
+// Flush sends what has been written so far on to the client, if the underlying ResponseWriter supports it
+func (rec *responseRecorder) Flush() {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying ResponseWriter
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+func (rec *responseRecorder) recordOutcome(r *http.Request, httpErr *HttpError) {
+	rec.request = r
+	rec.err = httpErr
+}
+
+// recordOutcome notes the request and error a response was written for, if w is a responseRecorder (or the timeoutWriter standing in for one)
+func recordOutcome(w http.ResponseWriter, r *http.Request, httpErr *HttpError) {
+	if recorder, ok := w.(interface {
+		recordOutcome(*http.Request, *HttpError)
+	}); ok {
+		recorder.recordOutcome(r, httpErr)
+	}
+}
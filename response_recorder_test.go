@@ -0,0 +1,26 @@
+package resthelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseRecorderFlush(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := &responseRecorder{ResponseWriter: w, span: noopSpan{}}
+	rec.Write([]byte("partial"))
+	if err := http.NewResponseController(rec).Flush(); err != nil {
+		t.Fatal("expected the recorder to support flushing, got", err)
+	}
+	if !w.Flushed || w.Body.String() != "partial" {
+		t.Error("expected the flush to reach the underlying ResponseWriter")
+	}
+
+	w = httptest.NewRecorder()
+	rec = &responseRecorder{ResponseWriter: w, span: noopSpan{}}
+	rec.Flush()
+	if !rec.wroteHeader || rec.status != http.StatusOK || w.Code != http.StatusOK {
+		t.Error("expected flushing before writing to send and record a 200, got", rec.status)
+	}
+}
@@ -0,0 +1,117 @@
+package resthelper_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func testCreateHandler(r *http.Request, input testJsonStruct) (resthelper.Response[testJsonStruct], *resthelper.HttpError) {
+	response := resthelper.Created("/things/"+input.Name, input)
+	response.Cookies = []*http.Cookie{{Name: "last_created", Value: input.Name}}
+	return response, nil
+}
+
+func TestResponseStatusAndHeaders(t *testing.T) {
+	handler := resthelper.JsonToJsonWrapper(testCreateHandler)
+
+	req := httptest.NewRequest("POST", "/things/", strings.NewReader(`{"Name":"Steve","Count":7}`))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Error("expected", http.StatusCreated, "got", rec.Code)
+	}
+	if location := rec.Header().Get("Location"); location != "/things/Steve" {
+		t.Error("unexpected Location:", location)
+	}
+	if cookie := rec.Result().Cookies(); len(cookie) != 1 || cookie[0].Value != "Steve" {
+		t.Error("unexpected cookies:", cookie)
+	}
+	var body testJsonStruct
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Count != 7 {
+		t.Error("expected only the body to be serialized, got", rec.Body.String())
+	}
+}
+
+func TestResponseWithoutBody(t *testing.T) {
+	handler := resthelper.JsonResponseWrapper(func(r *http.Request) (resthelper.Response[any], *resthelper.HttpError) {
+		return resthelper.NewResponse[any](http.StatusNoContent, nil), nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Error("expected an empty 204, got", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPointerResponse(t *testing.T) {
+	router := mux.NewRouter()
+	resthelper.Register(router, http.MethodGet, "/things/{name}", resthelper.JsonResponse(func(r *http.Request) (*resthelper.Response[string], *resthelper.HttpError) {
+		if name := mux.Vars(r)["name"]; name != "nothing" {
+			response := resthelper.Accepted(name)
+			return &response, nil
+		}
+		return nil, nil
+	}))
+	if routes := resthelper.Routes(router); len(routes) != 1 || routes[0].ResponseType != reflect.TypeFor[string]() {
+		t.Error("expected the route table to look through the pointer, got", routes)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/things/steve", nil))
+	if rec.Code != http.StatusAccepted || rec.Body.String() != `"steve"` {
+		t.Error("expected the pointed to response, got", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/things/nothing", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `""` {
+		t.Error("expected a nil response to be sent as an empty one, got", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	var reported error
+	postHookStatus := make(chan int, 1)
+	postHookErr := make(chan *resthelper.HttpError, 1)
+	handler := resthelper.JsonResponseWrapperWithHooks(
+		[]resthelper.PreRequestHook{},
+		func(r *http.Request) (map[string]any, *resthelper.HttpError) {
+			return map[string]any{"updates": make(chan int)}, nil
+		},
+		[]resthelper.PostResponseHook{func(httpErr *resthelper.HttpError, status int) {
+			postHookStatus <- status
+			postHookErr <- httpErr
+		}},
+		resthelper.WithMarshalErrorHook(func(r *http.Request, value any, err error) { reported = err }),
+	)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Error("expected", http.StatusInternalServerError, "got", rec.Code)
+	}
+	if rec.Header().Get("Content-Type") != "text/plain" {
+		t.Error("expected the usual error response, got", rec.Header().Get("Content-Type"))
+	}
+	if reported == nil {
+		t.Error("marshal error hook was not called")
+	}
+	if message, _, _ := strings.Cut(rec.Body.String(), "\n"); message != "failed to encode response" {
+		t.Error("expected a generic error without the encoder's details, got", message)
+	}
+	if status := <-postHookStatus; status != http.StatusInternalServerError {
+		t.Error("post response hook did not see the failure, got", status)
+	}
+	if httpErr := <-postHookErr; httpErr == nil || !strings.Contains(httpErr.Error(), "chan int") {
+		t.Error("expected post response hooks to see the underlying error, got", httpErr)
+	}
+}
@@ -1,27 +1,41 @@
 package resthelper
 
 import (
-	"fmt"
+	"encoding/json"
 	"net/http"
 )
 
-func writeCommonHeaders(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-}
+const problemJsonContentType = "application/problem+json"
 
-func respondWithError(w http.ResponseWriter, httpErr *HttpError, postResponseHooks []PostResponseHook) {
-	w.Header().Set("Content-Type", "text/plain")
-	w.WriteHeader(httpErr.Status)
-	w.Write([]byte(httpErr.Error()))
-	callPostResponseHooks(postResponseHooks, httpErr, httpErr.Status)
-}
+// plainTextRequestIDPrefix separates the request ID from the message in plain text error bodies, which ReadHttpError splits them at
+const plainTextRequestIDPrefix = "\nrequest ID: "
 
-func recoverToErrorResponse(w http.ResponseWriter, postResponseHooks []PostResponseHook) {
-	if r := recover(); r != nil {
-		msg := "goroutine panic"
-		fmt.Println(msg, r)
-		respondWithError(w, NewHttpErrF(http.StatusInternalServerError, msg), postResponseHooks)
+// respondWithError writes httpErr as application/problem+json if the client accepts it, falling back to text/plain
+// problem details include the request's ID as a requestId member; plain text responses end with it on a line of its own
+func respondWithError(w http.ResponseWriter, r *http.Request, httpErr *HttpError) {
+	recordOutcome(w, r, httpErr)
+	var body []byte
+	if acceptsProblemJson(r) {
+		problem := httpErr.Problem()
+		if id := RequestID(r.Context()); id != "" {
+			if _, exists := problem["requestId"]; !exists {
+				problem["requestId"] = id
+			}
+		}
+		var err error
+		body, err = json.Marshal(problem)
+		if err == nil {
+			w.Header().Set("Content-Type", problemJsonContentType)
+		}
 	}
+	if body == nil {
+		w.Header().Set("Content-Type", "text/plain")
+		message := httpErr.Error()
+		if id := RequestID(r.Context()); id != "" {
+			message += plainTextRequestIDPrefix + id
+		}
+		body = []byte(message)
+	}
+	w.WriteHeader(httpErr.Status)
+	w.Write(body)
 }
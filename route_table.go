@@ -0,0 +1,172 @@
+package resthelper
+
+import (
+	"cmp"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/unicycle/slices_ext"
+)
+
+// RouteInfo describes a route registered through a Group or Register, for generating documentation and clients
+type RouteInfo struct {
+	Method       string
+	Path         string       // the full path template, including any group prefixes
+	RequestType  reflect.Type // nil if the handler doesn't read a request body
+	ResponseType reflect.Type // nil for no content handlers; the body type for handlers returning Response[T]
+
+	Summary     string
+	Description string
+	Tags        []string
+	OperationID string
+	Status      int   // the status of a successful response, if the handler doesn't use the default 200 OK (or 204 No Content)
+	Errors      []int // the error statuses the handler is documented to return
+
+	PreRequestHooks   []PreRequestHook
+	PostResponseHooks []PostResponseHook
+	Options           []Option
+
+	Route *mux.Route
+}
+
+// RouteOption customizes a single route as it is registered
+type RouteOption func(*RouteInfo)
+
+// Summary sets a short, one line description of what a route does
+func Summary(summary string) RouteOption {
+	return func(info *RouteInfo) {
+		info.Summary = summary
+	}
+}
+
+// Description sets a longer explanation of a route
+func Description(description string) RouteOption {
+	return func(info *RouteInfo) {
+		info.Description = description
+	}
+}
+
+// Tags groups a route with others in generated documentation
+func Tags(tags ...string) RouteOption {
+	return func(info *RouteInfo) {
+		info.Tags = append(info.Tags, tags...)
+	}
+}
+
+// OperationID sets a unique name for a route, used for method names in generated clients
+func OperationID(id string) RouteOption {
+	return func(info *RouteInfo) {
+		info.OperationID = id
+	}
+}
+
+// SuccessStatus documents the status a route responds with when it succeeds, for handlers that return a Response[T] with a status other than 200 OK
+func SuccessStatus(status int) RouteOption {
+	return func(info *RouteInfo) {
+		info.Status = status
+	}
+}
+
+// Errors documents the error statuses a route may respond with
+func Errors(statuses ...int) RouteOption {
+	return func(info *RouteInfo) {
+		info.Errors = append(info.Errors, statuses...)
+	}
+}
+
+// RouteHooks adds hooks to a single route, running after any inherited from its group
+func RouteHooks(preRequestHooks []PreRequestHook, postResponseHooks []PostResponseHook) RouteOption {
+	return func(info *RouteInfo) {
+		info.PreRequestHooks = slices_ext.Concatenate(info.PreRequestHooks, preRequestHooks)
+		info.PostResponseHooks = slices_ext.Concatenate(info.PostResponseHooks, postResponseHooks)
+	}
+}
+
+// RouteOptions applies wrapper options to a single route, after any inherited from its group
+func RouteOptions(opts ...Option) RouteOption {
+	return func(info *RouteInfo) {
+		info.Options = slices_ext.Concatenate(info.Options, opts)
+	}
+}
+
+// Register wraps endpoint and registers it on router for method requests to path, recording it in the route table listed by Routes(router)
+func Register(router *mux.Router, method, path string, endpoint Endpoint, opts ...RouteOption) *mux.Route {
+	return NewGroup(router, "", GroupConfig{}).Handle(method, path, endpoint, opts...)
+}
+
+// Routes lists the routes registered through Register or a Group on router or any of its subrouters, in registration order
+func Routes(router *mux.Router) []RouteInfo {
+	entries := []tableEntry{}
+	router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		if table, ok := route.GetHandler().(*routeTable); ok {
+			entries = append(entries, table.list()...)
+		}
+		return nil
+	})
+	slices.SortFunc(entries, func(a, b tableEntry) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+	routes := make([]RouteInfo, len(entries))
+	for i, entry := range entries {
+		routes[i] = entry.info
+	}
+	return routes
+}
+
+// routeTable records the routes registered through Groups created from a router
+// it is kept on the router itself, as the handler of a build only route that never matches a request, so it lives exactly as long as the router does
+type routeTable struct {
+	lock    sync.RWMutex
+	entries []tableEntry
+}
+
+type tableEntry struct {
+	seq  uint64 // orders routes recorded in the tables of different subrouters
+	info RouteInfo
+}
+
+var (
+	routeSeq atomic.Uint64
+	// tableLock keeps two Groups created from the same router at once from adding a table each
+	tableLock sync.Mutex
+)
+
+// tableFor returns the table kept on router, adding one if it has none yet
+func tableFor(router *mux.Router) *routeTable {
+	tableLock.Lock()
+	defer tableLock.Unlock()
+	var table *routeTable
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if found, ok := route.GetHandler().(*routeTable); ok {
+			table = found
+		}
+		// only router's own routes are of interest, not those of its subrouters
+		return mux.SkipRouter
+	})
+	if table == nil {
+		table = &routeTable{}
+		router.NewRoute().BuildOnly().Handler(table)
+	}
+	return table
+}
+
+// ServeHTTP is never called, since build only routes don't match requests; it only makes the table storable as a route's handler
+func (table *routeTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	http.NotFound(w, r)
+}
+
+func (table *routeTable) add(info RouteInfo) {
+	table.lock.Lock()
+	defer table.lock.Unlock()
+	table.entries = append(table.entries, tableEntry{seq: routeSeq.Add(1), info: info})
+}
+
+func (table *routeTable) list() []tableEntry {
+	table.lock.RLock()
+	defer table.lock.RUnlock()
+	return slices.Clone(table.entries)
+}
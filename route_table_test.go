@@ -0,0 +1,79 @@
+package resthelper_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func TestRoutes(t *testing.T) {
+	router := mux.NewRouter()
+	api := resthelper.NewGroup(router, "/api", resthelper.GroupConfig{
+		PreRequestHooks: []resthelper.PreRequestHook{testAdminHook},
+	})
+	api.Post("/things", resthelper.JsonToJson(testCreateHandler),
+		resthelper.Summary("Create a thing"),
+		resthelper.Tags("things"),
+		resthelper.RouteHooks([]resthelper.PreRequestHook{unauthorizedTestHook}, nil),
+	)
+	api.Group("/v2", resthelper.GroupConfig{}).Delete("/things/{id}", resthelper.NoContent(testCorsHandler), resthelper.OperationID("deleteThing"))
+	resthelper.Register(router, http.MethodGet, "/health", resthelper.JsonResponse(func(r *http.Request) (bool, *resthelper.HttpError) {
+		return true, nil
+	}))
+
+	routes := resthelper.Routes(router)
+	if len(routes) != 3 {
+		t.Fatal("expected 3 routes, got", len(routes))
+	}
+
+	create := routes[0]
+	if create.Method != "POST" || create.Path != "/api/things" || create.Summary != "Create a thing" || len(create.Tags) != 1 {
+		t.Error("unexpected route info:", create)
+	}
+	if create.RequestType != reflect.TypeFor[testJsonStruct]() || create.ResponseType != reflect.TypeFor[testJsonStruct]() {
+		t.Error("expected request and response types to be captured, with Response[T] unwrapped:", create.RequestType, create.ResponseType)
+	}
+	if len(create.PreRequestHooks) != 2 {
+		t.Error("expected group and route hooks, got", len(create.PreRequestHooks))
+	}
+
+	remove := routes[1]
+	if remove.Path != "/api/v2/things/{id}" || remove.OperationID != "deleteThing" || remove.RequestType != nil || remove.ResponseType != nil {
+		t.Error("unexpected route info:", remove)
+	}
+
+	health := routes[2]
+	if health.Method != "GET" || health.Path != "/health" || health.RequestType != nil || health.ResponseType != reflect.TypeFor[bool]() {
+		t.Error("unexpected route info:", health)
+	}
+
+	if len(resthelper.Routes(mux.NewRouter())) != 0 {
+		t.Error("expected route tables to be separate for each router")
+	}
+}
+
+func unauthorizedTestHook(r *http.Request) *resthelper.HttpError {
+	return resthelper.NewHttpErrF(http.StatusUnauthorized, "unauthorized")
+}
+
+func TestRoutesOfSubrouters(t *testing.T) {
+	router := mux.NewRouter()
+	resthelper.Register(router, http.MethodGet, "/health", resthelper.NoContent(testCorsHandler))
+	admin := resthelper.NewGroup(router.PathPrefix("/admin").Subrouter(), "", resthelper.GroupConfig{})
+	admin.Delete("/things/{id}", resthelper.NoContent(testCorsHandler))
+	resthelper.Register(router, http.MethodPost, "/things", resthelper.NoContent(testCorsHandler))
+
+	paths := []string{}
+	for _, route := range resthelper.Routes(router) {
+		paths = append(paths, route.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"/health", "/admin/things/{id}", "/things"}) {
+		t.Error("expected the subrouter's routes in registration order, got", paths)
+	}
+	if routes := resthelper.Routes(mux.NewRouter()); len(routes) != 0 {
+		t.Error("expected no routes for a new router, got", routes)
+	}
+}
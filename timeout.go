@@ -0,0 +1,177 @@
+package resthelper
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// WithTimeout limits how long the PreRequestHooks and handler may take; their request's context is cancelled at the deadline, and the client receives the timeout error (see WithTimeoutError)
+// whatever the handler returns after the deadline is dropped; handlers should watch r.Context() so they stop working on requests nobody is waiting for
+// set it per route with RouteOptions, or for a whole Group; zero, the default, means no timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithTimeoutError changes the error sent when a request times out, which is a 504 Gateway Timeout by default
+func WithTimeoutError(httpErr *HttpError) Option {
+	return func(cfg *config) {
+		cfg.timeoutErr = httpErr
+	}
+}
+
+func defaultTimeoutError() *HttpError {
+	return NewHttpErrF(http.StatusGatewayTimeout, "request timed out")
+}
+
+// handlerPanic carries a panic out of the goroutine serving a request with a timeout, along with that goroutine's stack
+type handlerPanic struct {
+	value any
+	stack []byte
+}
+
+// serveWithTimeout runs serve in its own goroutine with a deadline, buffering its response so that only one of it and the timeout error ever reaches rec
+func (handler *wrappedHandler) serveWithTimeout(rec *responseRecorder, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), handler.cfg.timeout)
+	defer cancel()
+	tw := &timeoutWriter{header: rec.Header().Clone()}
+	done := make(chan *handlerPanic, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			var p *handlerPanic
+			if recovered := recover(); recovered != nil {
+				p = &handlerPanic{value: recovered, stack: debug.Stack()}
+			}
+			if tw.finish(ctx.Err() != nil) {
+				// nothing is waiting for the result any more, but neither a panic nor a handler outliving its deadline should go unnoticed
+				info := ResponseInfo{
+					Request:   r,
+					Route:     routeTemplate(r),
+					Duration:  time.Since(start),
+					RequestID: RequestID(r.Context()),
+				}
+				if p == nil {
+					handler.cfg.logLateHandler(info)
+				} else if p.value != http.ErrAbortHandler {
+					info.Panic, info.PanicStack = p.value, p.stack
+					handler.cfg.logPanic(info)
+				}
+			}
+			done <- p
+		}()
+		handler.serve(tw, r.WithContext(ctx))
+	}()
+	var p *handlerPanic
+	finished := false
+	select {
+	case p = <-done:
+		finished = true
+	case <-ctx.Done():
+	}
+	if tw.timeOut() {
+		respondWithError(rec, r, handler.cfg.timeoutErr)
+		return
+	}
+	if !finished {
+		// the handler finished in time, just as the deadline passed
+		p = <-done
+	}
+	tw.flushTo(rec, p)
+}
+
+// timeoutWriter holds the response of a handler with a timeout until it either finishes or runs out of time
+type timeoutWriter struct {
+	lock        sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+	finished    bool
+	request     *http.Request
+	err         *HttpError
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.status = status
+	tw.wroteHeader = true
+}
+
+func (tw *timeoutWriter) Write(data []byte) (int, error) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.status = http.StatusOK
+		tw.wroteHeader = true
+	}
+	return tw.body.Write(data)
+}
+
+func (tw *timeoutWriter) recordOutcome(r *http.Request, httpErr *HttpError) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if !tw.timedOut {
+		tw.request = r
+		tw.err = httpErr
+	}
+}
+
+// finish notes that the handler has returned (or panicked), reporting whether it was too late
+// a handler returning after the deadline is late even if the timeout hasn't been noticed yet, so that handlers giving up when their context is cancelled don't have their result sent
+func (tw *timeoutWriter) finish(late bool) bool {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	tw.finished = true
+	tw.timedOut = tw.timedOut || late
+	return tw.timedOut
+}
+
+// timeOut stops any further writes unless the handler has already finished in time, reporting whether the timeout error should be sent instead
+func (tw *timeoutWriter) timeOut() bool {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if tw.finished && !tw.timedOut {
+		return false
+	}
+	tw.timedOut = true
+	return true
+}
+
+// flushTo sends the finished handler's buffered response on to rec, then re-raises its panic, if any, for finish to recover as if it had happened on this goroutine
+func (tw *timeoutWriter) flushTo(rec *responseRecorder, p *handlerPanic) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if tw.request != nil {
+		recordOutcome(rec, tw.request, tw.err)
+	}
+	header := rec.Header()
+	clear(header)
+	for key, values := range tw.header {
+		header[key] = values
+	}
+	if tw.wroteHeader {
+		rec.WriteHeader(tw.status)
+		rec.Write(tw.body.Bytes())
+	}
+	if p != nil {
+		panic(p)
+	}
+}
@@ -0,0 +1,110 @@
+package resthelper_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+func TestTimeout(t *testing.T) {
+	hookDeadline := make(chan bool, 1)
+	handlerErr := make(chan error, 1)
+	infos := make(chan resthelper.ResponseInfo, 1)
+	handler := resthelper.JsonResponseWrapperWithHooks([]resthelper.PreRequestHook{func(r *http.Request) *resthelper.HttpError {
+		_, ok := r.Context().Deadline()
+		hookDeadline <- ok
+		return nil
+	}}, func(r *http.Request) (string, *resthelper.HttpError) {
+		<-r.Context().Done()
+		handlerErr <- r.Context().Err()
+		return "too late", nil
+	}, nil, resthelper.WithTimeout(10*time.Millisecond), resthelper.WithSynchronousHooks(),
+		resthelper.WithResponseHooks(func(info resthelper.ResponseInfo) {
+			infos <- info
+		}))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if rec.Code != http.StatusGatewayTimeout || plainTextError(t, rec) != "request timed out" {
+		t.Error("expected the timeout error, got", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("X-Request-ID") == "" {
+		t.Error("expected headers set before the handler ran to be kept")
+	}
+	if !<-hookDeadline {
+		t.Error("expected hooks to run with the deadline")
+	}
+	if err := <-handlerErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected the handler's context to be cancelled at the deadline, got", err)
+	}
+	if info := <-infos; info.Status != http.StatusGatewayTimeout || info.Err == nil {
+		t.Error("expected hooks to see the timeout, got", info)
+	}
+}
+
+// logLines sends each line written to it on a channel
+type logLines chan string
+
+func (lines logLines) Write(data []byte) (int, error) {
+	lines <- string(data)
+	return len(data), nil
+}
+
+func TestTimeoutLateResult(t *testing.T) {
+	logged := make(logLines, 1)
+	handler := resthelper.JsonResponseWrapper(func(r *http.Request) (resthelper.Response[string], *resthelper.HttpError) {
+		<-r.Context().Done()
+		return resthelper.Response[string]{Body: "too late", Status: http.StatusCreated, Headers: http.Header{"X-Late": {"yes"}}}, nil
+	}, resthelper.WithTimeout(5*time.Millisecond), resthelper.WithTimeoutError(resthelper.NewHttpErrF(http.StatusServiceUnavailable, "busy")),
+		resthelper.WithLogger(slog.New(slog.NewTextHandler(logged, nil))))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	// the late handler is only logged once the wrapper has finished trying to write its response
+	if line := <-logged; !strings.Contains(line, "handler finished after its timeout") {
+		t.Error("expected the late handler to be logged, got", line)
+	}
+	if rec.Code != http.StatusServiceUnavailable || plainTextError(t, rec) != "busy" || rec.Header().Get("X-Late") != "" {
+		t.Error("expected the late result to be dropped, got", rec.Code, rec.Body.String(), rec.Header())
+	}
+}
+
+func TestTimeoutInTime(t *testing.T) {
+	handler := resthelper.JsonResponseWrapper(func(r *http.Request) (resthelper.Response[string], *resthelper.HttpError) {
+		return resthelper.Response[string]{Body: "hello", Status: http.StatusCreated, Headers: http.Header{"X-Quick": {"yes"}}}, nil
+	}, resthelper.WithTimeout(time.Second))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/quick", nil))
+	if rec.Code != http.StatusCreated || rec.Body.String() != `"hello"` || rec.Header().Get("X-Quick") != "yes" || rec.Header().Get("X-Request-ID") == "" {
+		t.Error("expected the handler's response, got", rec.Code, rec.Body.String(), rec.Header())
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	var handledStack []byte
+	handler := resthelper.NoContentWrapper(func(r *http.Request) *resthelper.HttpError {
+		panic("oh no")
+	}, resthelper.WithTimeout(time.Second), resthelper.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		resthelper.WithPanicHandler(func(r *http.Request, recovered any, stack []byte) *resthelper.HttpError {
+			handledStack = stack
+			return resthelper.NewHttpErrF(http.StatusInternalServerError, "recovered from %v", recovered)
+		}))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodDelete, "/things/1", nil))
+	if rec.Code != http.StatusInternalServerError || plainTextError(t, rec) != "recovered from oh no" {
+		t.Error("expected the panic to be recovered, got", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(string(handledStack), "timeout_test.go") {
+		t.Error("expected the stack of the handler's goroutine:", string(handledStack))
+	}
+}
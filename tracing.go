@@ -0,0 +1,194 @@
+package resthelper
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// TraceID and SpanID identify traces and spans as in the W3C Trace Context specification
+type TraceID [16]byte
+type SpanID [8]byte
+
+// SpanContext identifies a span, as carried between services in the W3C traceparent header
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set; the zero SpanContext means there is no parent
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// TraceParent formats sc as a version 00 traceparent header value
+func (sc SpanContext) TraceParent() string {
+	flags := 0
+	if sc.Sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%x-%x-%02x", sc.TraceID[:], sc.SpanID[:], flags)
+}
+
+// ParseTraceParent reads a traceparent header value, reporting false if it is malformed or names an invalid trace
+func ParseTraceParent(header string) (SpanContext, bool) {
+	sc := SpanContext{}
+	header = strings.TrimSpace(header)
+	// later versions may append fields, but must keep these ones where they are
+	if len(header) < 55 || (len(header) > 55 && header[55] != '-') {
+		return sc, false
+	}
+	version, traceID, spanID, flags := header[0:2], header[3:35], header[36:52], header[53:55]
+	if header[2] != '-' || header[35] != '-' || header[52] != '-' || version == "ff" || (version == "00" && len(header) != 55) {
+		return sc, false
+	}
+	var versionBytes, flagBytes [1]byte
+	fields := []struct {
+		decoded []byte
+		encoded string
+	}{{versionBytes[:], version}, {sc.TraceID[:], traceID}, {sc.SpanID[:], spanID}, {flagBytes[:], flags}}
+	for _, field := range fields {
+		// the specification only allows lower case hex
+		if strings.ToLower(field.encoded) != field.encoded {
+			return sc, false
+		}
+		if _, err := hex.Decode(field.decoded, []byte(field.encoded)); err != nil {
+			return sc, false
+		}
+	}
+	sc.Sampled = flagBytes[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+// Span is a single timed operation within a trace
+type Span interface {
+	SpanContext() SpanContext
+	SetAttributes(attrs ...slog.Attr)
+	// RecordError marks the span as failed
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans, and is small enough to adapt to the OpenTelemetry SDK or any other tracing library
+type Tracer interface {
+	// Start begins a span named name, as a child of parent
+	// for the server span of a request, parent comes from its traceparent header, and is invalid if it had none
+	Start(ctx context.Context, name string, parent SpanContext) Span
+}
+
+// WithTracer traces requests: a server span per request, continuing the trace in its traceparent header, with child spans for each PreRequestHook, decoding the request, the handler and encoding the response
+// error statuses are recorded on the spans they occur in; Route.Call sends the current span on to the next service
+func WithTracer(tracer Tracer) Option {
+	return func(cfg *config) {
+		cfg.tracer = tracer
+	}
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the current span of a traced request, or a span that does nothing if there isn't one
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// StartSpan starts a child of the current span with the tracer of the wrapper serving the request ctx belongs to, for tracing work inside handlers
+// it returns ctx unchanged and a span that does nothing if tracing is off
+func StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	return configFromContext(ctx).startSpan(ctx, name)
+}
+
+func (cfg *config) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if cfg.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	span := cfg.tracer.Start(ctx, name, SpanFromContext(ctx).SpanContext())
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// startServerSpan starts the span covering the whole of r, returning r with the span in its context
+func (cfg *config) startServerSpan(r *http.Request) (*http.Request, Span) {
+	if cfg.tracer == nil {
+		return r, noopSpan{}
+	}
+	parent, _ := ParseTraceParent(r.Header.Get("traceparent"))
+	name, route := r.Method, routeTemplate(r)
+	if route != "" {
+		name += " " + route
+	}
+	span := cfg.tracer.Start(r.Context(), name, parent)
+	span.SetAttributes(
+		slog.String("http.request.method", r.Method),
+		slog.String("http.route", route),
+		slog.String("url.path", r.URL.Path),
+	)
+	return r.WithContext(context.WithValue(r.Context(), spanKey{}, span)), span
+}
+
+// startHookSpan starts the span of a single PreRequestHook
+// hooks still receive r itself rather than a request carrying the span, so that requests enriched by ContextHooks aren't lost
+func (cfg *config) startHookSpan(r *http.Request, hook PreRequestHook) Span {
+	if cfg.tracer == nil {
+		return noopSpan{}
+	}
+	_, span := cfg.startSpan(r.Context(), "pre-request hook")
+	span.SetAttributes(slog.String("hook", hookName(hook)))
+	return span
+}
+
+// traceHandler calls handler within a span, passing it a request whose context holds the span so its own spans and outgoing calls nest beneath it
+func traceHandler[T any](r *http.Request, handler func(*http.Request) (T, *HttpError)) (T, *HttpError) {
+	ctx, span := StartSpan(r.Context(), "handler")
+	if ctx != r.Context() {
+		r = r.WithContext(ctx)
+	}
+	result, httpErr := handler(r)
+	endSpan(span, httpErr)
+	return result, httpErr
+}
+
+// endSpan records httpErr (if any) on span and ends it
+func endSpan(span Span, httpErr *HttpError) {
+	if httpErr != nil {
+		span.SetAttributes(slog.Int("http.response.status_code", httpErr.Status))
+		span.RecordError(httpErr)
+	}
+	span.End()
+}
+
+// endServerSpan records the outcome of a request on its server span and ends it
+func endServerSpan(span Span, info ResponseInfo) {
+	span.SetAttributes(slog.Int("http.response.status_code", info.Status))
+	if info.RequestID != "" {
+		span.SetAttributes(slog.String("request.id", info.RequestID))
+	}
+	if info.Panic != nil {
+		span.RecordError(fmt.Errorf("panic: %v", info.Panic))
+	} else if info.Err != nil {
+		span.RecordError(info.Err)
+	}
+	span.End()
+}
+
+// hookName names a PreRequestHook after its function, for its span
+func hookName(hook PreRequestHook) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(hook).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SpanContext() SpanContext   { return SpanContext{} }
+func (noopSpan) SetAttributes(...slog.Attr) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
@@ -0,0 +1,175 @@
+package resthelper_test
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+)
+
+// memoryTracer records every span it starts, standing in for an exporter
+type memoryTracer struct {
+	lock  sync.Mutex
+	spans []*memorySpan
+}
+
+type memorySpan struct {
+	name    string
+	parent  resthelper.SpanContext
+	context resthelper.SpanContext
+	attrs   map[string]slog.Value
+	err     error
+	ended   bool
+}
+
+func (tracer *memoryTracer) Start(ctx context.Context, name string, parent resthelper.SpanContext) resthelper.Span {
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+	span := &memorySpan{name: name, parent: parent, attrs: map[string]slog.Value{}}
+	span.context.TraceID = parent.TraceID
+	if !parent.IsValid() {
+		span.context.TraceID[0] = 1
+	}
+	binary.BigEndian.PutUint64(span.context.SpanID[:], uint64(len(tracer.spans)+1))
+	tracer.spans = append(tracer.spans, span)
+	return span
+}
+
+func (tracer *memoryTracer) named(name string) *memorySpan {
+	for _, span := range tracer.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func (span *memorySpan) SpanContext() resthelper.SpanContext { return span.context }
+func (span *memorySpan) RecordError(err error)               { span.err = err }
+func (span *memorySpan) End()                                { span.ended = true }
+
+func (span *memorySpan) SetAttributes(attrs ...slog.Attr) {
+	for _, attr := range attrs {
+		span.attrs[attr.Key] = attr.Value
+	}
+}
+
+func allowAll(r *http.Request) *resthelper.HttpError {
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &memoryTracer{}
+	var outgoing string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outgoing = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Name":"widget","Count":1}`))
+	}))
+	defer upstream.Close()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{id}", resthelper.JsonToJsonWrapperWithHooks([]resthelper.PreRequestHook{allowAll}, func(r *http.Request, request testBoundRequest) (testJsonStruct, *resthelper.HttpError) {
+		item, err := testLookupItem.Call(r.Context(), resthelper.NewClient(upstream.URL), request)
+		if err != nil {
+			return item, resthelper.NewHttpErr(http.StatusBadGateway, err)
+		}
+		return item, nil
+	}, nil, resthelper.WithTracer(tracer), resthelper.WithSynchronousHooks()))
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	server := tracer.named("GET /items/{id}")
+	if server == nil {
+		t.Fatal("expected a server span, got", tracer.spans)
+	}
+	if server.parent.TraceParent() != "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01" {
+		t.Error("expected the server span to continue the inbound trace, got", server.parent.TraceParent())
+	}
+	if server.attrs["http.route"].String() != "/items/{id}" || server.attrs["http.response.status_code"].Int64() != http.StatusOK {
+		t.Error("unexpected server span attributes", server.attrs)
+	}
+	handler := tracer.named("handler")
+	for name, parent := range map[string]*memorySpan{
+		"pre-request hook": server,
+		"handler":          server,
+		"decode request":   handler,
+		"encode response":  server,
+	} {
+		span := tracer.named(name)
+		if span == nil {
+			t.Error("expected a span named", name)
+			continue
+		}
+		if span.parent != parent.context {
+			t.Error("expected", name, "to be a child of", parent.name)
+		}
+		if !span.ended || span.err != nil {
+			t.Error("expected", name, "to have ended without error")
+		}
+	}
+	if hook := tracer.named("pre-request hook").attrs["hook"].String(); !strings.HasSuffix(hook, ".allowAll") {
+		t.Error("expected the hook's function name, got", hook)
+	}
+	if outgoing != handler.context.TraceParent() {
+		t.Error("expected calls made by the handler to carry its span, got", outgoing)
+	}
+}
+
+func TestTracingErrors(t *testing.T) {
+	tracer := &memoryTracer{}
+	handler := resthelper.NoContentWrapperWithHooks([]resthelper.PreRequestHook{func(r *http.Request) *resthelper.HttpError {
+		return resthelper.NewHttpErrF(http.StatusUnauthorized, "no token")
+	}}, func(r *http.Request) *resthelper.HttpError {
+		return nil
+	}, nil, resthelper.WithTracer(tracer))
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/items/1", nil))
+
+	server := tracer.named("DELETE")
+	if server == nil || server.parent.IsValid() {
+		t.Fatal("expected a root server span without a traceparent, got", tracer.spans)
+	}
+	if server.err == nil || server.attrs["http.response.status_code"].Int64() != http.StatusUnauthorized || !server.ended {
+		t.Error("expected the error status on the server span, got", server.attrs, server.err)
+	}
+	hook := tracer.named("pre-request hook")
+	if hook == nil || hook.err == nil || hook.attrs["http.response.status_code"].Int64() != http.StatusUnauthorized {
+		t.Error("expected the error status on the hook span")
+	}
+	if tracer.named("handler") != nil {
+		t.Error("expected no handler span after a hook failed")
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	for header, valid := range map[string]bool{
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01":       true,
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00":       true,
+		"01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra": true,
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra": false,
+		"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01":       false,
+		"00-0AF7651916CD43DD8448EB211C80319C-b7ad6b7169203331-01":       false,
+		"00-00000000000000000000000000000000-b7ad6b7169203331-01":       false,
+		"00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01":       false,
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b716920333x-01":       false,
+		"00_0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01":       false,
+		"": false,
+	} {
+		sc, ok := resthelper.ParseTraceParent(header)
+		if ok != valid {
+			t.Error("expected", header, "valid:", valid)
+		}
+		if ok && header[:2] == "00" && sc.TraceParent() != header {
+			t.Error("expected", header, "to round trip, got", sc.TraceParent())
+		}
+	}
+}
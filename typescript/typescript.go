@@ -0,0 +1,401 @@
+// Package typescript emits TypeScript types and a small typed fetch client from the OpenAPI document the openapi package generates
+package typescript
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/preston-wagner/go-resthelper/openapi"
+)
+
+// Generate writes a TypeScript module declaring a type for every schema component in document and a function for every operation
+// enums become a union of their values plus a const object naming each one, and generic instantiations like Page_User become ordinary types
+func Generate(document *openapi.Document) []byte {
+	gen := generator{}
+	gen.line("// Code generated by resthelper-ts from %s %s. DO NOT EDIT.", document.Info.Title, document.Info.Version)
+	gen.line("")
+	gen.line("%s", strings.TrimSpace(runtime))
+	for _, name := range sortedKeys(document.Components.Schemas) {
+		gen.line("")
+		gen.component(name, document.Components.Schemas[name])
+	}
+	for _, path := range sortedKeys(document.Paths) {
+		item := document.Paths[path]
+		for _, method := range sortedKeys(item) {
+			gen.line("")
+			gen.operation(path, method, item[method])
+		}
+	}
+	return gen.out.Bytes()
+}
+
+// runtime is shared by every generated operation
+const runtime = `
+export interface ClientOptions {
+	baseUrl: string;
+	headers?: Record<string, string>;
+	fetch?: typeof fetch;
+}
+
+export interface Problem {
+	type: string;
+	title: string;
+	status: number;
+	detail?: string;
+	instance?: string;
+	[extension: string]: unknown;
+}
+
+// HttpError is thrown for error responses, carrying the server's problem details
+export class HttpError extends Error {
+	constructor(public status: number, public problem: Problem) {
+		super(problem.detail || problem.title);
+	}
+}
+
+async function request<T>(
+	options: ClientOptions,
+	method: string,
+	path: string,
+	query: Record<string, unknown>,
+	headers: Record<string, unknown>,
+	body?: unknown,
+): Promise<T> {
+	const search = new URLSearchParams();
+	for (const [name, value] of Object.entries(query)) {
+		for (const item of Array.isArray(value) ? value : [value]) {
+			if (item !== undefined && item !== null) {
+				search.append(name, String(item));
+			}
+		}
+	}
+	const init: RequestInit = {
+		method,
+		headers: { ...options.headers, Accept: "application/json, application/problem+json" },
+	};
+	for (const [name, value] of Object.entries(headers)) {
+		if (value !== undefined && value !== null) {
+			(init.headers as Record<string, string>)[name] = String(value);
+		}
+	}
+	if (body !== undefined) {
+		(init.headers as Record<string, string>)["Content-Type"] = "application/json";
+		init.body = JSON.stringify(body);
+	}
+	const queryString = search.toString();
+	const url = options.baseUrl.replace(/\/$/, "") + path + (queryString === "" ? "" : "?" + queryString);
+	const response = await (options.fetch || fetch)(url, init);
+	const text = await response.text();
+	if (!response.ok) {
+		let problem: Problem;
+		try {
+			problem = JSON.parse(text);
+		} catch {
+			problem = { type: "about:blank", title: response.statusText, status: response.status, detail: text };
+		}
+		throw new HttpError(response.status, problem);
+	}
+	return (text === "" ? undefined : JSON.parse(text)) as T;
+}
+`
+
+type generator struct {
+	out bytes.Buffer
+}
+
+func (gen *generator) line(format string, args ...any) {
+	fmt.Fprintf(&gen.out, format, args...)
+	gen.out.WriteByte('\n')
+}
+
+func (gen *generator) component(name string, schema *openapi.Schema) {
+	typeName := identifier(name, true)
+	if name == "Problem" {
+		// declared by the runtime
+		return
+	}
+	if constants := enumConstants(schema); constants != nil {
+		gen.line("export type %s = %s;", typeName, gen.tsType(schema))
+		gen.line("export const %s = {", typeName)
+		for _, constant := range constants {
+			gen.line("\t%s: %s,", constant.name, constant.value)
+		}
+		gen.line("} as const;")
+		return
+	}
+	if schema.Type == "object" && schema.Properties != nil {
+		gen.line("export interface %s %s", typeName, gen.objectType(schema))
+		return
+	}
+	gen.line("export type %s = %s;", typeName, gen.tsType(schema))
+}
+
+type enumConstant struct {
+	name  string
+	value string
+}
+
+// enumConstants names each value of a string enum, or returns nil for anything else
+func enumConstants(schema *openapi.Schema) []enumConstant {
+	if len(schema.Enum) == 0 {
+		return nil
+	}
+	constants := []enumConstant{}
+	for _, value := range schema.Enum {
+		text, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		constants = append(constants, enumConstant{name: identifier(text, true), value: strconv.Quote(text)})
+	}
+	return constants
+}
+
+// tsType spells out the TypeScript type of values matching schema
+func (gen *generator) tsType(schema *openapi.Schema) string {
+	if schema == nil {
+		return "unknown"
+	}
+	if schema.Ref != "" {
+		return identifier(strings.TrimPrefix(schema.Ref, "#/components/schemas/"), true)
+	}
+	if len(schema.AnyOf) > 0 {
+		types := make([]string, len(schema.AnyOf))
+		for i, option := range schema.AnyOf {
+			types[i] = gen.tsType(option)
+		}
+		return strings.Join(types, " | ")
+	}
+	if len(schema.Enum) > 0 {
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = literal(value)
+		}
+		return strings.Join(values, " | ")
+	}
+	switch typeName := schema.Type.(type) {
+	case string:
+		return gen.singleType(schema, typeName)
+	case []string:
+		types := make([]string, len(typeName))
+		for i, single := range typeName {
+			types[i] = gen.singleType(schema, single)
+		}
+		return strings.Join(types, " | ")
+	default:
+		return "unknown"
+	}
+}
+
+func (gen *generator) singleType(schema *openapi.Schema, typeName string) string {
+	switch typeName {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "null":
+		return "null"
+	case "array":
+		itemType := gen.tsType(schema.Items)
+		if strings.ContainsAny(itemType, " |") {
+			itemType = "(" + itemType + ")"
+		}
+		return itemType + "[]"
+	case "object":
+		if schema.Properties != nil {
+			return gen.objectType(schema)
+		}
+		if additional, ok := schema.AdditionalProperties.(*openapi.Schema); ok {
+			return "Record<string, " + gen.tsType(additional) + ">"
+		}
+		return "Record<string, unknown>"
+	default:
+		return "unknown"
+	}
+}
+
+// objectType spells out an object type with a member per property, marking those not required as optional
+func (gen *generator) objectType(schema *openapi.Schema) string {
+	if len(schema.Properties) == 0 {
+		return "{}"
+	}
+	lines := []string{"{"}
+	for _, name := range sortedKeys(schema.Properties) {
+		optional := "?"
+		if slices.Contains(schema.Required, name) {
+			optional = ""
+		}
+		lines = append(lines, fmt.Sprintf("\t%s%s: %s;", propertyName(name), optional, gen.tsType(schema.Properties[name])))
+	}
+	lines = append(lines, "}")
+	return strings.Join(lines, "\n")
+}
+
+// operation writes a function calling a single operation, taking its parameters as one object and its request body (if any) as another
+func (gen *generator) operation(path, method string, operation *openapi.Operation) {
+	name := operation.OperationID
+	if name == "" {
+		name = method + " " + strings.NewReplacer("{", "by ", "}", "").Replace(path)
+	}
+	name = identifier(name, false)
+
+	params := []string{"options: ClientOptions"}
+	if len(operation.Parameters) > 0 {
+		members := []string{}
+		required := false
+		for _, parameter := range operation.Parameters {
+			optional := "?"
+			if parameter.Required {
+				optional, required = "", true
+			}
+			members = append(members, fmt.Sprintf("%s%s: %s", propertyName(parameter.Name), optional, gen.tsType(parameter.Schema)))
+		}
+		optional := ""
+		if !required && (operation.RequestBody == nil || !operation.RequestBody.Required) {
+			// a required body can't follow an optional argument
+			optional = "?"
+		}
+		params = append(params, fmt.Sprintf("params%s: { %s }", optional, strings.Join(members, "; ")))
+	}
+	if operation.RequestBody != nil {
+		optional := ""
+		if !operation.RequestBody.Required {
+			optional = "?"
+		}
+		params = append(params, fmt.Sprintf("body%s: %s", optional, gen.tsType(contentSchema(operation.RequestBody.Content))))
+	}
+
+	if operation.Summary != "" {
+		gen.line("// %s", operation.Summary)
+	}
+	gen.line("export function %s(%s): Promise<%s> {", name, strings.Join(params, ", "), gen.responseType(operation))
+	pathExpression := strconv.Quote(path)
+	query, headers := []string{}, []string{}
+	for _, parameter := range operation.Parameters {
+		access := "params" + accessor(parameter.Name)
+		if !parameter.Required {
+			access = "params?" + optionalAccessor(parameter.Name)
+		}
+		switch parameter.In {
+		case "path":
+			pathExpression = strings.Replace(pathExpression, "{"+parameter.Name+"}", `" + encodeURIComponent(String(`+access+`)) + "`, 1)
+		case "query":
+			query = append(query, strconv.Quote(parameter.Name)+": "+access)
+		case "header":
+			headers = append(headers, strconv.Quote(parameter.Name)+": "+access)
+		}
+	}
+	pathExpression = strings.TrimSuffix(strings.TrimPrefix(pathExpression, `"" + `), ` + ""`)
+	body := ""
+	if operation.RequestBody != nil {
+		body = ", body"
+	}
+	gen.line("\treturn request(options, %s, %s, %s, %s%s);", strconv.Quote(strings.ToUpper(method)), pathExpression, objectLiteral(query), objectLiteral(headers), body)
+	gen.line("}")
+}
+
+// responseType is the type of an operation's successful response body, or void if it has none
+func (gen *generator) responseType(operation *openapi.Operation) string {
+	for _, status := range sortedKeys(operation.Responses) {
+		code, err := strconv.Atoi(status)
+		if err != nil || code < http.StatusOK || code >= http.StatusMultipleChoices {
+			continue
+		}
+		if schema := contentSchema(operation.Responses[status].Content); schema != nil {
+			return gen.tsType(schema)
+		}
+		return "void"
+	}
+	return "void"
+}
+
+func contentSchema(content map[string]*openapi.MediaType) *openapi.Schema {
+	for _, mediaType := range sortedKeys(content) {
+		return content[mediaType].Schema
+	}
+	return nil
+}
+
+var wordBoundary = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// identifier turns an arbitrary name into a camelCase (or PascalCase, if exported) TypeScript identifier
+func identifier(name string, pascal bool) string {
+	words := wordBoundary.Split(name, -1)
+	builder := strings.Builder{}
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		runes := []rune(word)
+		if builder.Len() > 0 || pascal {
+			runes[0] = unicode.ToUpper(runes[0])
+		} else {
+			runes[0] = unicode.ToLower(runes[0])
+		}
+		builder.WriteString(string(runes))
+	}
+	result := builder.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "_" + result
+	}
+	return result
+}
+
+var plainProperty = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// propertyName quotes property names that aren't valid identifiers, like X-Tenant
+func propertyName(name string) string {
+	if plainProperty.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func accessor(name string) string {
+	if plainProperty.MatchString(name) {
+		return "." + name
+	}
+	return "[" + strconv.Quote(name) + "]"
+}
+
+func optionalAccessor(name string) string {
+	if plainProperty.MatchString(name) {
+		return "." + name
+	}
+	return ".[" + strconv.Quote(name) + "]"
+}
+
+func objectLiteral(members []string) string {
+	if len(members) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(members, ", ") + " }"
+}
+
+func literal(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(typed)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package typescript_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/preston-wagner/go-resthelper"
+	"github.com/preston-wagner/go-resthelper/openapi"
+	"github.com/preston-wagner/go-resthelper/typescript"
+)
+
+type testColor string
+
+func (testColor) EnumValues() []any {
+	return []any{"red", "dark-blue"}
+}
+
+type testPage[T any] struct {
+	Items []T `json:"items"`
+	Next  *T  `json:"next"`
+}
+
+type testWidget struct {
+	ID     int64             `path:"id" json:"-"`
+	Tenant string            `header:"X-Tenant" json:"-"`
+	Name   string            `json:"name"`
+	Color  testColor         `json:"color,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Parent *testWidget       `json:"parent,omitempty"`
+}
+
+type testSearch struct {
+	Query string   `query:"q" json:"-"`
+	Tags  []string `query:"tag" json:"-"`
+}
+
+func testDocument(t *testing.T) *openapi.Document {
+	router := mux.NewRouter()
+	resthelper.Register(router, http.MethodPut, "/widgets/{id}", resthelper.JsonToJson(func(r *http.Request, widget testWidget) (testWidget, *resthelper.HttpError) {
+		return widget, nil
+	}), resthelper.OperationID("updateWidget"), resthelper.Summary("Update a widget"))
+	resthelper.Register(router, http.MethodGet, "/widgets", resthelper.JsonToJson(func(r *http.Request, search testSearch) (testPage[testWidget], *resthelper.HttpError) {
+		return testPage[testWidget]{}, nil
+	}))
+	resthelper.Register(router, http.MethodDelete, "/widgets/{id}", resthelper.NoContent(func(r *http.Request) *resthelper.HttpError {
+		return nil
+	}))
+
+	// round trip through json, as the command does
+	encoded, err := json.Marshal(openapi.Generate(router, openapi.Info{Title: "Widgets", Version: "1.0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := &openapi.Document{}
+	if err := json.Unmarshal(encoded, document); err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
+
+func TestGenerate(t *testing.T) {
+	generated := string(typescript.Generate(testDocument(t)))
+
+	expected := []string{
+		"// Code generated by resthelper-ts from Widgets 1.0. DO NOT EDIT.",
+		"export class HttpError extends Error {",
+		// enums as a union plus named constants
+		`export type TestColor = "red" | "dark-blue";`,
+		"\tDarkBlue: \"dark-blue\",",
+		// json tags, optional and nullable fields, maps and recursive references
+		"export interface TestWidget {\n\tcolor?: TestColor;\n\tlabels?: Record<string, string>;\n\tname: string;\n\tparent?: TestWidget | null;\n}",
+		// generic instantiations
+		"export interface TestPageTestWidget {\n\titems: TestWidget[];\n\tnext?: TestWidget | null;\n}",
+		// one function per route, named after its operation ID or its method and path
+		"// Update a widget\nexport function updateWidget(options: ClientOptions, params: { id: number; \"X-Tenant\"?: string }, body?: TestWidget): Promise<TestWidget> {",
+		`return request(options, "PUT", "/widgets/" + encodeURIComponent(String(params.id)), {}, { "X-Tenant": params?.["X-Tenant"] }, body);`,
+		"export function getWidgets(options: ClientOptions, params?: { q?: string; tag?: string[] }): Promise<TestPageTestWidget> {",
+		`return request(options, "GET", "/widgets", { "q": params?.q, "tag": params?.tag }, {});`,
+		"export function deleteWidgetsById(options: ClientOptions, params: { id: string }): Promise<void> {",
+	}
+	for _, snippet := range expected {
+		if !strings.Contains(generated, snippet) {
+			t.Error("expected generated code to contain\n", snippet, "\n\ngot\n", generated)
+		}
+	}
+}
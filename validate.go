@@ -0,0 +1,272 @@
+package resthelper
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Validator can be implemented by request types to check rules that struct tags can't express
+// returning ValidationErrors reports several problems at once, with field paths relative to the implementing value
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes a single rule a request field failed to satisfy
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects every FieldError found in a request, rather than just the first
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, fieldErr := range errs {
+		if fieldErr.Field == "" {
+			messages[i] = fieldErr.Message
+		} else {
+			messages[i] = fieldErr.Field + ": " + fieldErr.Message
+		}
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// ValidateRequest checks value against its `validate:"..."` and `pattern:"..."` struct tags and any Validate() methods, descending into nested structs, slices and maps
+// supported rules are required, min=N, max=N (lengths for strings, slices and maps; values for numbers) and oneof=a b c; pattern holds a regular expression strings must match
+// all violations are returned together in a single 422 HttpError, with the list of FieldErrors under the "errors" problem extension
+func ValidateRequest(value any) *HttpError {
+	errs := validateValue(reflect.ValueOf(value), "")
+	if len(errs) == 0 {
+		return nil
+	}
+	httpErr := NewHttpErr(http.StatusUnprocessableEntity, errs)
+	httpErr.Extensions = map[string]any{"errors": errs}
+	return httpErr
+}
+
+var validatorType = reflect.TypeFor[Validator]()
+
+func validateValue(value reflect.Value, path string) ValidationErrors {
+	errs := ValidationErrors{}
+	if !value.IsValid() {
+		return errs
+	}
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return errs
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		for _, rule := range fieldRules(value.Type()) {
+			fieldPath := joinFieldPath(path, rule.name)
+			field := value.Field(rule.index)
+			errs = append(errs, rule.check(field, fieldPath)...)
+			errs = append(errs, validateValue(field, fieldPath)...)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			errs = append(errs, validateValue(value.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			errs = append(errs, validateValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()))...)
+		}
+	}
+	return append(errs, callValidator(value, path)...)
+}
+
+func callValidator(value reflect.Value, path string) ValidationErrors {
+	var validator Validator
+	if value.Type().Implements(validatorType) {
+		validator = value.Interface().(Validator)
+	} else if reflect.PointerTo(value.Type()).Implements(validatorType) {
+		if !value.CanAddr() {
+			copied := reflect.New(value.Type())
+			copied.Elem().Set(value)
+			value = copied.Elem()
+		}
+		validator = value.Addr().Interface().(Validator)
+	} else {
+		return nil
+	}
+	err := validator.Validate()
+	if err == nil {
+		return nil
+	}
+	if fieldErrs, ok := err.(ValidationErrors); ok {
+		prefixed := make(ValidationErrors, len(fieldErrs))
+		for i, fieldErr := range fieldErrs {
+			prefixed[i] = FieldError{Field: joinFieldPath(path, fieldErr.Field), Message: fieldErr.Message}
+		}
+		return prefixed
+	}
+	return ValidationErrors{{Field: path, Message: err.Error()}}
+}
+
+func joinFieldPath(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+	if child == "" {
+		return parent
+	}
+	return parent + "." + child
+}
+
+// ValidationRules holds the rules declared by a struct field's validate and pattern tags
+type ValidationRules struct {
+	Required bool
+	Min      *float64
+	Max      *float64
+	OneOf    []string
+	Pattern  *regexp.Regexp
+}
+
+// FieldValidationRules parses the validate and pattern tags of field, as used by ValidateRequest
+func FieldValidationRules(field reflect.StructField) (ValidationRules, error) {
+	rules := ValidationRules{}
+	if tag, ok := field.Tag.Lookup("validate"); ok {
+		for _, part := range strings.Split(tag, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch name {
+			case "":
+			case "required":
+				rules.Required = true
+			case "min", "max":
+				limit, err := strconv.ParseFloat(arg, 64)
+				if err != nil {
+					return rules, fmt.Errorf("invalid %s rule on %s: %w", name, field.Name, err)
+				}
+				if name == "min" {
+					rules.Min = &limit
+				} else {
+					rules.Max = &limit
+				}
+			case "oneof":
+				rules.OneOf = strings.Fields(arg)
+			default:
+				return rules, fmt.Errorf("unknown validation rule %q on %s", name, field.Name)
+			}
+		}
+	}
+	if pattern, ok := field.Tag.Lookup("pattern"); ok {
+		var err error
+		rules.Pattern, err = regexp.Compile(pattern)
+		if err != nil {
+			return rules, fmt.Errorf("invalid pattern on %s: %w", field.Name, err)
+		}
+	}
+	return rules, nil
+}
+
+type fieldRule struct {
+	ValidationRules
+	index int
+	name  string
+}
+
+var fieldRuleCache sync.Map // reflect.Type -> []fieldRule
+
+// fieldRules parses the validation tags of every exported field of a struct type, panicking on malformed tags since those are programming errors
+func fieldRules(t reflect.Type) []fieldRule {
+	if cached, ok := fieldRuleCache.Load(t); ok {
+		return cached.([]fieldRule)
+	}
+	rules := []fieldRule{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		validationRules, err := FieldValidationRules(field)
+		if err != nil {
+			panic(fmt.Sprintf("resthelper: %s.%v", t.Name(), err))
+		}
+		rule := fieldRule{ValidationRules: validationRules, index: i, name: fieldPathName(field)}
+		if field.Anonymous {
+			// embedded structs report their fields as if they were declared on the parent
+			rule.name = ""
+		}
+		rules = append(rules, rule)
+	}
+	fieldRuleCache.Store(t, rules)
+	return rules
+}
+
+// fieldPathName names a field the way clients see it: by its json name, falling back to its parameter name or Go name
+func fieldPathName(field reflect.StructField) string {
+	if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+		return jsonName
+	}
+	for _, tag := range paramTags {
+		if name := field.Tag.Get(tag); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func (rule fieldRule) check(field reflect.Value, path string) ValidationErrors {
+	if field.IsZero() {
+		if rule.Required {
+			return ValidationErrors{{Field: path, Message: "is required"}}
+		}
+		// a zero value is indistinguishable from an absent field, so optional fields are only checked when set
+		return nil
+	}
+	for field.Kind() == reflect.Pointer || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	errs := ValidationErrors{}
+	var size float64
+	sizeNoun := ""
+	switch field.Kind() {
+	case reflect.String:
+		size, sizeNoun = float64(len([]rune(field.String()))), "length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		size, sizeNoun = float64(field.Len()), "length"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size, sizeNoun = float64(field.Int()), "value"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size, sizeNoun = float64(field.Uint()), "value"
+	case reflect.Float32, reflect.Float64:
+		size, sizeNoun = field.Float(), "value"
+	}
+	if sizeNoun != "" {
+		if rule.Min != nil && size < *rule.Min {
+			errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("%s must be at least %v", sizeNoun, *rule.Min)})
+		}
+		if rule.Max != nil && size > *rule.Max {
+			errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("%s must be at most %v", sizeNoun, *rule.Max)})
+		}
+	}
+	if len(rule.OneOf) > 0 {
+		actual := fmt.Sprint(field.Interface())
+		found := false
+		for _, allowed := range rule.OneOf {
+			if actual == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, FieldError{Field: path, Message: "must be one of: " + strings.Join(rule.OneOf, ", ")})
+		}
+	}
+	if rule.Pattern != nil && field.Kind() == reflect.String && !rule.Pattern.MatchString(field.String()) {
+		errs = append(errs, FieldError{Field: path, Message: "must match pattern " + rule.Pattern.String()})
+	}
+	return errs
+}
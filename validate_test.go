@@ -0,0 +1,112 @@
+package resthelper_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/preston-wagner/go-resthelper"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" pattern:"^[0-9]{5}$"`
+}
+
+type testSignup struct {
+	Username  string        `json:"username" validate:"required,min=3,max=16" pattern:"^[a-z0-9_]+$"`
+	Age       *int          `json:"age" validate:"min=13"`
+	Plan      string        `json:"plan" validate:"oneof=free pro"`
+	Addresses []testAddress `json:"addresses" validate:"max=2"`
+	Password  string        `json:"password"`
+	Confirm   string        `json:"confirm"`
+}
+
+func (signup testSignup) Validate() error {
+	if signup.Password != signup.Confirm {
+		return resthelper.ValidationErrors{{Field: "confirm", Message: "does not match password"}}
+	}
+	return nil
+}
+
+type testNote struct {
+	Text string `json:"text"`
+}
+
+func (note *testNote) Validate() error {
+	if strings.Contains(note.Text, "spam") {
+		return errors.New("looks like spam")
+	}
+	return nil
+}
+
+func validationErrors(t *testing.T, handler func(http.ResponseWriter, *http.Request), body string) []resthelper.FieldError {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Accept", "application/problem+json")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code == http.StatusOK {
+		return nil
+	}
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatal("expected", http.StatusUnprocessableEntity, "got", rec.Code, rec.Body.String())
+	}
+	var problem struct {
+		Errors []resthelper.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	return problem.Errors
+}
+
+func TestValidateRequest(t *testing.T) {
+	handler := resthelper.JsonToJsonWrapper(func(r *http.Request, input testSignup) (testSignup, *resthelper.HttpError) {
+		return input, nil
+	})
+
+	valid := `{"username":"steve_7","age":30,"plan":"pro","addresses":[{"city":"Boston","zip":"02110"}],"password":"x","confirm":"x"}`
+	if errs := validationErrors(t, handler, valid); errs != nil {
+		t.Error("expected valid request to pass, got", errs)
+	}
+
+	invalid := `{"username":"St","age":9,"plan":"enterprise","addresses":[{"zip":"1"},{"city":"Austin"},{"city":"Denver"}],"password":"x","confirm":"y"}`
+	expected := map[string]bool{
+		"username":          true, // too short
+		"age":               true,
+		"plan":              true,
+		"addresses":         true,
+		"addresses[0].city": true,
+		"addresses[0].zip":  true,
+		"confirm":           true,
+	}
+	errs := validationErrors(t, handler, invalid)
+	found := map[string]int{}
+	for _, fieldErr := range errs {
+		found[fieldErr.Field]++
+		if !expected[fieldErr.Field] {
+			t.Error("unexpected field error:", fieldErr)
+		}
+	}
+	for field := range expected {
+		if found[field] == 0 {
+			t.Error("missing field error for", field)
+		}
+	}
+	if found["username"] != 2 {
+		t.Error("expected both the length and pattern violations for username, got", found["username"])
+	}
+}
+
+func TestValidatePointerReceiver(t *testing.T) {
+	handler := resthelper.JsonToJsonWrapper(func(r *http.Request, input testNote) (testNote, *resthelper.HttpError) {
+		return input, nil
+	})
+	errs := validationErrors(t, handler, `{"text":"buy spam now"}`)
+	if len(errs) != 1 || errs[0].Field != "" || errs[0].Message != "looks like spam" {
+		t.Error("unexpected validation errors:", errs)
+	}
+}